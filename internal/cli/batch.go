@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kaofelix/skulls/internal/install"
+	"github.com/kaofelix/skulls/internal/tui"
+)
+
+type batchOptions = install.BatchOptions
+type batchResult = install.BatchResult
+
+var runAddBatchUI = tui.RunBatchInstall
+var runAddBatchPlain = func(ctx context.Context, items []install.BatchItem, opts batchOptions) []batchResult {
+	opts.GitStdout = io.Discard
+	opts.GitStderr = io.Discard
+	opts.Progress = func(i int, e install.Event) {
+		if e.Step == install.StepClone && !e.Done && e.Progress == nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", items[i], e.Message)
+		}
+	}
+	return install.InstallBatch(ctx, items, opts)
+}
+
+// batchItemsFromArgs reports whether add's positionals name several skills,
+// either as "<source>@<skill>..." or as "<source> <skill> <skill>...".
+func batchItemsFromArgs(position []string) ([]install.BatchItem, bool) {
+	if len(position) < 2 {
+		return nil, false
+	}
+	var items []install.BatchItem
+	for _, p := range position {
+		source, skillID, ok := splitSourceSkillShorthand(p)
+		if !ok {
+			items = nil
+			break
+		}
+		items = append(items, install.BatchItem{Source: source, SkillID: skillID})
+	}
+	if items != nil {
+		return items, true
+	}
+	if len(position) < 3 {
+		return nil, false
+	}
+	source := strings.TrimSpace(position[0])
+	for _, skillID := range position[1:] {
+		items = append(items, install.BatchItem{Source: source, SkillID: strings.TrimSpace(skillID)})
+	}
+	return items, true
+}
+
+// singleSource reports whether every item comes from the same repository,
+// so that one --ref makes sense for all of them.
+func singleSource(items []install.BatchItem) bool {
+	for _, it := range items[1:] {
+		if !install.SameSource(it.Source, items[0].Source) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseJobs(v string) (int, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid --jobs %q (use a number of 1 or more)", v)
+	}
+	return n, nil
+}
+
+// runAddBatch installs items and prints one line per skill; it fails if
+// any of them did.
+func runAddBatch(ctx context.Context, items []install.BatchItem, opts batchOptions, dirCtx installDirContext) int {
+	for i := range items {
+		items[i].Ref = opts.Ref
+		if items[i].SkillID == "" {
+			fmt.Fprint(os.Stderr, "skill-id must be non-empty\n")
+			return 2
+		}
+		if opts.SHA256 != "" && !install.IsArchiveSource(items[i].Source) {
+			fmt.Fprint(os.Stderr, "--sha256 only applies to archive sources (.tar.gz, .tgz, .zip)\n")
+			return 2
+		}
+	}
+
+	results, err := runAddBatchUI(ctx, items, opts)
+	if err != nil {
+		if !isNoTTYError(err) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		results = runAddBatchPlain(ctx, items, opts)
+	}
+
+	failed := 0
+	fmt.Println()
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("✗ %s: %v\n", r.Item, r.Err)
+			printGitErrorHint(r.Err, false)
+			continue
+		}
+		fmt.Printf("💀 %s → %s\n", r.Item, compactPath(r.InstalledPath))
+	}
+	fmt.Printf("\n%d installed, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	printInstallTip(dirCtx, opts.TargetDir)
+	return 0
+}
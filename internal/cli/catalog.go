@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+const catalogUsage = "Usage: skulls catalog pull [--bodies] [--limit <n>] [--query <q>]... [--no-cache]\n"
+
+const defaultCatalogLimit = 500
+
+var cacheDirFunc = defaultCacheDir
+var pullCatalogSnapshot = skillsapi.PullSnapshot
+
+type catalogPullArgs struct {
+	Bodies  bool
+	NoCache bool
+	Limit   int
+	Queries []string
+	Help    bool
+}
+
+func runCatalog(args []string) int {
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		fmt.Fprint(os.Stderr, catalogUsage)
+		return 0
+	}
+	if len(args) == 0 || args[0] != "pull" {
+		fmt.Fprint(os.Stderr, catalogUsage)
+		return 2
+	}
+	return runCatalogPull(args[1:])
+}
+
+func parseCatalogPullArgs(args []string) (catalogPullArgs, error) {
+	out := catalogPullArgs{Limit: defaultCatalogLimit}
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+
+		var value string
+		var name string
+		switch {
+		case a == "-h" || a == "--help":
+			out.Help = true
+			continue
+		case a == "--bodies":
+			out.Bodies = true
+			continue
+		case a == "--no-cache":
+			out.NoCache = true
+			continue
+		case a == "--limit" || a == "--query":
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			name, value = a, args[i]
+		case strings.HasPrefix(a, "--limit=") || strings.HasPrefix(a, "--query="):
+			name, value, _ = strings.Cut(a, "=")
+		default:
+			return out, fmt.Errorf("unexpected argument: %s", a)
+		}
+
+		switch name {
+		case "--limit":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return out, fmt.Errorf("--limit must be a positive number")
+			}
+			out.Limit = n
+		case "--query":
+			out.Queries = append(out.Queries, value)
+		}
+	}
+	return out, nil
+}
+
+func runCatalogPull(args []string) int {
+	parsed, err := parseCatalogPullArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		fmt.Fprint(os.Stderr, catalogUsage)
+		return 2
+	}
+	if parsed.Help {
+		fmt.Fprint(os.Stderr, catalogUsage)
+		return 0
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	httpClient, err := httpClientForRun(cfg, parsed.NoCache)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	registry, err := registryForRun(cfg, httpClient, githubAuthForRun(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	path, err := catalogSnapshotPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	opts := skillsapi.PullOptions{Limit: parsed.Limit, Queries: parsed.Queries, Bodies: parsed.Bodies}
+	if parsed.Bodies {
+		opts.Progress = func(done int, total int) {
+			fmt.Fprintf(os.Stderr, "\rFetching SKILL.md %d/%d", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+	snap, err := pullCatalogSnapshot(context.Background(), registry, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := skillsapi.WriteSnapshot(path, snap); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	bodies := 0
+	for _, s := range snap.Skills {
+		if s.Body != "" {
+			bodies++
+		}
+	}
+	fmt.Printf("Saved %d skill(s) (%d with SKILL.md) to %s\n", len(snap.Skills), bodies, compactPath(path))
+	return 0
+}
+
+// loadCatalogSnapshot reads the snapshot used by --offline.
+func loadCatalogSnapshot() (skillsapi.Snapshot, error) {
+	path, err := catalogSnapshotPath()
+	if err != nil {
+		return skillsapi.Snapshot{}, err
+	}
+	snap, err := skillsapi.ReadSnapshot(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return skillsapi.Snapshot{}, fmt.Errorf("no offline catalog at %s\nPull one while online first:\n  skulls catalog pull --bodies", compactPath(path))
+	}
+	return snap, err
+}
+
+func catalogSnapshotPath() (string, error) {
+	dir, err := cacheDirFunc()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "catalog.json"), nil
+}
+
+func defaultCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "skulls"), nil
+}
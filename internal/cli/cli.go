@@ -1,35 +1,58 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kaofelix/skulls/internal/gitutil"
 	"github.com/kaofelix/skulls/internal/install"
 	"github.com/kaofelix/skulls/internal/skillsapi"
 	"github.com/kaofelix/skulls/internal/tui"
 )
 
 type tuiSearchResult = tui.SearchResult
+type tuiSearchOptions = tui.SearchOptions
 type tuiInstallResult = tui.InstallResult
 type tuiSkill = skillsapi.Skill
+type installOptions = install.Options
 
 var runAddInstallUI = tui.RunInstall
-var runAddInstallPlain = func(source string, skillID string, targetDir string, force bool) (string, error) {
-	return install.InstallSkill(source, skillID, install.Options{TargetDir: targetDir, Force: force})
+var runAddInstallPlain = func(ctx context.Context, source string, skillID string, opts installOptions) (string, error) {
+	// git's own progress redraws with \r; print compact lines instead.
+	opts.GitStdout = io.Discard
+	opts.GitStderr = io.Discard
+	opts.Progress = plainCloneProgress(os.Stderr)
+	return install.InstallSkill(ctx, source, skillID, opts)
 }
 var runAddSelectFromSource = tui.RunSearchFromSource
-var runSearchUI = tui.RunSearch
+var runSearchUI = tui.RunSearchWithOptions
 var runSearchInstallUI = tui.RunInstall
 
+const (
+	addUsage    = "Usage: skulls add <source> [skill-id...] [--dir <target-dir>] [--ref <ref>] [--path <dir>] [--require-signed] [--sha256 <sum>] [--timeout <duration>] [--jobs <n>]\n"
+	searchUsage = "Usage: skulls [--dir <target-dir>] [--force] [--require-signed] [--catalog <file|url>] [--offline] [--no-cache] [--limit <n>]\n"
+)
+
 const helpText = `skulls — dead simple skills
 
 Usage:
-  skulls [--dir <target-dir>] [--force]          # interactive search
-  skulls add <source> [skill-id] [--dir <target-dir>]
+  skulls [--dir <target-dir>] [--force] [--catalog <file|url>] [--offline]   # interactive search
+  skulls add <source> [skill-id] [--dir <target-dir>] [--ref <ref>] [--require-signed]
+  skulls add <source@skill>... [--jobs <n>]                             # batch install
+  skulls verify [skill-id...] [--dir <target-dir>] [--restore]
+  skulls diff <skill-id> [--ref <ref>] [--dir <target-dir>] [--tui]
+  skulls doctor [--dir <target-dir>]
+  skulls index build <source[#ref]>... [-o <file>]
+  skulls catalog pull [--bodies] [--limit <n>] [--query <q>]... [--no-cache]
   skulls config set dir <path>
   skulls config get
 
@@ -38,9 +61,19 @@ Source:
   - Any git URL: https://..., git@..., file:///...
   - Local path to a git repo: ./path/to/repo
 
+Flags:
+  --ref <ref>         install a branch, tag or commit instead of the default branch
+  --require-signed    abort unless the commit (or --ref tag) has a trusted signature
+  --catalog <src>     search a static skills index (file or URL) instead of skills.sh
+  --offline           search the snapshot saved by "skulls catalog pull"
+  --no-cache          bypass the on-disk HTTP cache for this run
+  --limit <n>         load n search and popular results at a time (more load as you scroll)
+  --jobs <n>          clone up to n sources at once when adding several skills (default 4)
+
 Examples:
   skulls add obra/superpowers using-git-worktrees --dir ~/.pi/agent/skills
   skulls add obra/superpowers@test-driven-development --dir ~/.pi/agent/skills
+  skulls add obra/superpowers@brainstorming vercel-labs/agent-skills@web-design-guidelines
 `
 
 func Run(args []string) int {
@@ -56,6 +89,16 @@ func Run(args []string) int {
 		return runAdd(args[1:])
 	case "config":
 		return runConfig(args[1:])
+	case "verify":
+		return runVerify(args[1:])
+	case "diff":
+		return runDiff(args[1:])
+	case "doctor":
+		return runDoctor(args[1:])
+	case "index":
+		return runIndex(args[1:])
+	case "catalog":
+		return runCatalog(args[1:])
 	default:
 		if strings.HasPrefix(args[0], "-") {
 			return runSearch(args)
@@ -67,10 +110,16 @@ func Run(args []string) int {
 }
 
 type addArgs struct {
-	TargetDir string
-	Force     bool
-	Help      bool
-	Position  []string
+	TargetDir     string
+	Ref           string
+	Path          string
+	SHA256        string
+	Timeout       string
+	Jobs          string
+	Force         bool
+	RequireSigned bool
+	Help          bool
+	Position      []string
 }
 
 func parseAddArgs(args []string) (addArgs, error) {
@@ -93,6 +142,75 @@ func parseAddArgs(args []string) (addArgs, error) {
 			out.Force = true
 			continue
 		}
+		if flagMode && a == "--require-signed" {
+			out.RequireSigned = true
+			continue
+		}
+
+		if flagMode && a == "--ref" {
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.Ref = args[i]
+			continue
+		}
+		if flagMode && strings.HasPrefix(a, "--ref=") {
+			out.Ref = strings.TrimPrefix(a, "--ref=")
+			continue
+		}
+
+		if flagMode && a == "--path" {
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.Path = args[i]
+			continue
+		}
+		if flagMode && strings.HasPrefix(a, "--path=") {
+			out.Path = strings.TrimPrefix(a, "--path=")
+			continue
+		}
+
+		if flagMode && a == "--sha256" {
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.SHA256 = args[i]
+			continue
+		}
+		if flagMode && strings.HasPrefix(a, "--sha256=") {
+			out.SHA256 = strings.TrimPrefix(a, "--sha256=")
+			continue
+		}
+
+		if flagMode && a == "--timeout" {
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.Timeout = args[i]
+			continue
+		}
+		if flagMode && strings.HasPrefix(a, "--timeout=") {
+			out.Timeout = strings.TrimPrefix(a, "--timeout=")
+			continue
+		}
+
+		if flagMode && (a == "-j" || a == "--jobs") {
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.Jobs = args[i]
+			continue
+		}
+		if flagMode && strings.HasPrefix(a, "--jobs=") {
+			out.Jobs = strings.TrimPrefix(a, "--jobs=")
+			continue
+		}
 
 		if flagMode && (a == "-d" || a == "--dir") {
 			i++
@@ -121,15 +239,33 @@ func runAdd(args []string) int {
 	parsed, err := parseAddArgs(args)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
-		fmt.Fprint(os.Stderr, "Usage: skulls add <source> [skill-id] [--dir <target-dir>]\n")
+		fmt.Fprint(os.Stderr, addUsage)
 		return 2
 	}
 	if parsed.Help {
-		fmt.Fprint(os.Stderr, "Usage: skulls add <source> [skill-id] [--dir <target-dir>]\n")
+		fmt.Fprint(os.Stderr, addUsage)
 		return 0
 	}
-	if len(parsed.Position) < 1 || len(parsed.Position) > 2 {
-		fmt.Fprint(os.Stderr, "Usage: skulls add <source> [skill-id] [--dir <target-dir>]\n")
+	batchItems, isBatch := batchItemsFromArgs(parsed.Position)
+	if len(parsed.Position) < 1 || (len(parsed.Position) > 2 && !isBatch) {
+		fmt.Fprint(os.Stderr, addUsage)
+		return 2
+	}
+	if isBatch && strings.TrimSpace(parsed.Path) != "" {
+		fmt.Fprint(os.Stderr, "--path can't be used when installing several skills\n")
+		return 2
+	}
+	if isBatch && strings.TrimSpace(parsed.Ref) != "" && !singleSource(batchItems) {
+		fmt.Fprint(os.Stderr, "--ref can't be used when installing from several sources\n")
+		return 2
+	}
+	if !isBatch && strings.TrimSpace(parsed.Jobs) != "" {
+		fmt.Fprint(os.Stderr, "--jobs only applies when installing several skills\n")
+		return 2
+	}
+	jobs, err := parseJobs(parsed.Jobs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 2
 	}
 
@@ -144,6 +280,43 @@ func runAdd(args []string) int {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 2
 	}
+	sources := []string{source}
+	if isBatch {
+		sources = sources[:0]
+		for _, it := range batchItems {
+			sources = append(sources, it.Source)
+		}
+	} else if s, _, ok := splitSourceSkillShorthand(source); ok && len(parsed.Position) == 1 {
+		sources[0] = s
+	}
+	opts, err := installOptionsForRun(targetDir, true, sources...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	opts.Ref = strings.TrimSpace(parsed.Ref)
+	opts.Path = strings.TrimSpace(parsed.Path)
+	opts.RequireSigned = opts.RequireSigned || parsed.RequireSigned
+	opts.SHA256 = strings.TrimSpace(parsed.SHA256)
+	if opts.SHA256 != "" && !isBatch && !install.IsArchiveSource(source) {
+		fmt.Fprint(os.Stderr, "--sha256 only applies to archive sources (.tar.gz, .tgz, .zip)\n")
+		return 2
+	}
+	timeout, err := installTimeoutForRun(parsed.Timeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	// Discovery and the selector only stop on Ctrl+C; the timeout bounds the
+	// install itself.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if isBatch {
+		installCtx, cancel := withInstallTimeout(ctx, timeout)
+		defer cancel()
+		return runAddBatch(installCtx, batchItems, batchOptions{Options: opts, Jobs: jobs}, dirCtx)
+	}
 
 	var skillID string
 	if len(parsed.Position) == 2 {
@@ -157,15 +330,19 @@ func runAdd(args []string) int {
 			source = shorthandSource
 			skillID = shorthandSkill
 		} else {
-			selection, err := runAddSelectFromSource(source)
+			selection, err := runAddSelectFromSource(ctx, source, opts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				printGitErrorHint(err, true)
 				return 1
 			}
 			if !selection.Selected {
 				return 0
 			}
 			skillID = strings.TrimSpace(selection.Skill.SkillID)
+			if opts.Path == "" {
+				opts.Path = selection.Skill.Path
+			}
 			if skillID == "" {
 				fmt.Fprint(os.Stderr, "Error: selected skill is empty\n")
 				return 1
@@ -173,12 +350,15 @@ func runAdd(args []string) int {
 		}
 	}
 
-	installRes, err := runAddInstallUI(targetDir, true, skillsapi.Skill{Source: source, SkillID: skillID})
+	installCtx, cancel := withInstallTimeout(ctx, timeout)
+	defer cancel()
+	installRes, err := runAddInstallUI(installCtx, skillsapi.Skill{Source: source, SkillID: skillID}, opts)
 	if err != nil {
 		if isNoTTYError(err) {
-			installedPath, plainErr := runAddInstallPlain(source, skillID, targetDir, true)
+			installedPath, plainErr := runAddInstallPlain(installCtx, source, skillID, opts)
 			if plainErr != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", plainErr)
+				printGitErrorHint(plainErr, true)
 				return 1
 			}
 			printInstallSuccess(skillID, source, installedPath)
@@ -198,6 +378,24 @@ func runAdd(args []string) int {
 	return 0
 }
 
+// printGitErrorHint explains a failed git command, followed by the end of
+// git's output when that wasn't already shown (withOutput).
+func printGitErrorHint(err error, withOutput bool) {
+	var gitErr *gitutil.GitError
+	if !errors.As(err, &gitErr) {
+		return
+	}
+	if hint := gitErr.Hint(); hint != "" {
+		fmt.Fprintf(os.Stderr, "  %s\n", hint)
+	}
+	if !withOutput {
+		return
+	}
+	for _, line := range gitErr.Tail(5) {
+		fmt.Fprintf(os.Stderr, "  │ %s\n", line)
+	}
+}
+
 func isNoTTYError(err error) bool {
 	if err == nil {
 		return false
@@ -207,9 +405,14 @@ func isNoTTYError(err error) bool {
 }
 
 type searchArgs struct {
-	TargetDir string
-	Force     bool
-	Help      bool
+	TargetDir     string
+	Catalog       string
+	Limit         int
+	Offline       bool
+	NoCache       bool
+	Force         bool
+	RequireSigned bool
+	Help          bool
 }
 
 func parseSearchArgs(args []string) (searchArgs, error) {
@@ -225,6 +428,15 @@ func parseSearchArgs(args []string) (searchArgs, error) {
 		case a == "-f" || a == "--force":
 			out.Force = true
 			continue
+		case a == "--require-signed":
+			out.RequireSigned = true
+			continue
+		case a == "--offline":
+			out.Offline = true
+			continue
+		case a == "--no-cache":
+			out.NoCache = true
+			continue
 		case a == "-d" || a == "--dir":
 			i++
 			if i >= len(args) {
@@ -235,6 +447,31 @@ func parseSearchArgs(args []string) (searchArgs, error) {
 		case strings.HasPrefix(a, "--dir="):
 			out.TargetDir = strings.TrimPrefix(a, "--dir=")
 			continue
+		case a == "--catalog":
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.Catalog = args[i]
+			continue
+		case strings.HasPrefix(a, "--catalog="):
+			out.Catalog = strings.TrimPrefix(a, "--catalog=")
+			continue
+		case a == "--limit" || strings.HasPrefix(a, "--limit="):
+			value, ok := strings.CutPrefix(a, "--limit=")
+			if !ok {
+				i++
+				if i >= len(args) {
+					return out, fmt.Errorf("%s requires a value", a)
+				}
+				value = args[i]
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n <= 0 {
+				return out, fmt.Errorf("--limit must be a positive number")
+			}
+			out.Limit = n
+			continue
 		default:
 			if strings.HasPrefix(a, "-") {
 				return out, fmt.Errorf("unknown flag: %s", a)
@@ -261,6 +498,9 @@ func splitSourceSkillShorthand(source string) (string, string, bool) {
 		return "", "", false
 	}
 	repoParts := strings.Split(repo, "/")
+	if len(repoParts) == 3 && gitutil.IsHostname(repoParts[0]) {
+		repoParts = repoParts[1:]
+	}
 	if len(repoParts) != 2 || strings.TrimSpace(repoParts[0]) == "" || strings.TrimSpace(repoParts[1]) == "" {
 		return "", "", false
 	}
@@ -271,11 +511,11 @@ func runSearch(args []string) int {
 	parsed, err := parseSearchArgs(args)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
-		fmt.Fprint(os.Stderr, "Usage: skulls [--dir <target-dir>] [--force]\n")
+		fmt.Fprint(os.Stderr, searchUsage)
 		return 2
 	}
 	if parsed.Help {
-		fmt.Fprint(os.Stderr, "Usage: skulls [--dir <target-dir>] [--force]\n")
+		fmt.Fprint(os.Stderr, searchUsage)
 		return 0
 	}
 	targetDir, dirCtx, err := resolveInstallDirForRun(parsed.TargetDir)
@@ -283,8 +523,52 @@ func runSearch(args []string) int {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 2
 	}
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	// Resolved once: it may run `gh auth token` for every host.
+	gh := githubAuthForRun(cfg)
+	opts, err := installOptionsFor(cfg, gh, targetDir, parsed.Force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	opts.RequireSigned = opts.RequireSigned || parsed.RequireSigned
+	httpClient, err := httpClientForRun(cfg, parsed.NoCache)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	registry, err := registryForRun(cfg, httpClient, gh)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	if c := strings.TrimSpace(parsed.Catalog); c != "" {
+		registry = &skillsapi.Index{Location: expandUserPath(c), HTTP: httpClient, GitHubToken: gh.Token, GitHubHosts: gh.Hosts}
+	}
+	searchOpts := tuiSearchOptions{Policy: opts.Policy, Registry: registry, TargetDir: targetDir, CheckUpdate: updateCheckFor(opts)}
+	if cfg.SearchLimit < 0 || cfg.PopularLimit < 0 {
+		fmt.Fprint(os.Stderr, "Error: search_limit and popular_limit must be positive\n")
+		return 2
+	}
+	searchOpts.SearchLimit, searchOpts.PopularLimit = cfg.SearchLimit, cfg.PopularLimit
+	if parsed.Limit > 0 {
+		searchOpts.SearchLimit, searchOpts.PopularLimit = parsed.Limit, parsed.Limit
+	}
+	if parsed.Offline {
+		snap, err := loadCatalogSnapshot()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		searchOpts.Registry = snap
+		searchOpts.SnapshotAt = snap.PulledAt
+	}
 
-	searchRes, err := runSearchUI()
+	searchRes, err := runSearchUI(searchOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
@@ -292,8 +576,29 @@ func runSearch(args []string) int {
 	if !searchRes.Selected {
 		return 0
 	}
+	skill := searchRes.Skill
+	opts.Ref = strings.TrimSpace(skill.Ref)
+	opts.Path = strings.TrimSpace(skill.Path)
+	if md := installedMetadata(searchRes); md != nil {
+		// Update and reinstall replace the installed copy from where it
+		// was installed.
+		skill = tuiSkill{Source: md.ReinstallSource(), SkillID: md.SkillID}
+		opts.Force = true
+		opts.Path = md.Path
+		opts.Ref = md.Ref
+		if searchRes.Action == tui.ActionReinstall {
+			opts.Ref = md.ReinstallRef()
+			opts.SHA256 = md.SHA256
+		}
+	}
 
-	installRes, err := runSearchInstallUI(targetDir, parsed.Force, searchRes.Skill)
+	ctx, cancel, err := installContextForRun("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	defer cancel()
+	installRes, err := runSearchInstallUI(ctx, skill, opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
@@ -303,11 +608,28 @@ func runSearch(args []string) int {
 		return 1
 	}
 
-	printInstallSuccess(searchRes.Skill.SkillID, searchRes.Skill.Source, installRes.InstalledPath)
+	printInstallSuccess(skill.SkillID, skill.Source, installRes.InstalledPath)
 	printInstallTip(dirCtx, targetDir)
 	return 0
 }
 
+// installedMetadata returns the metadata of the installed skill an update or
+// reinstall from the search UI applies to, or nil for plain installs.
+func installedMetadata(res tuiSearchResult) *install.Metadata {
+	if res.Action == tui.ActionInstall || res.Installed == nil {
+		return nil
+	}
+	return res.Installed.Metadata
+}
+
+// updateCheckFor returns the search UI's update check for installs made
+// with opts.
+func updateCheckFor(opts installOptions) func(context.Context, install.Metadata) (bool, error) {
+	return func(ctx context.Context, md install.Metadata) (bool, error) {
+		return install.UpdateAvailable(ctx, md, opts)
+	}
+}
+
 type installDirContext struct {
 	UsedFlag      bool
 	HasConfigured bool
@@ -335,6 +657,84 @@ func resolveInstallDirForRun(flagValue string) (string, installDirContext, error
 	return "", ctx, fmt.Errorf("install dir is not configured yet ☠️\nUse --dir <target-dir> for this run, or set a default:\n  skulls config set dir <path>")
 }
 
+// installOptionsForRun builds install options from the saved config, with
+// tokens for the hosts of sources.
+func installOptionsForRun(targetDir string, force bool, sources ...string) (installOptions, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return installOptions{}, err
+	}
+	return installOptionsFor(cfg, githubAuthForSources(cfg, sources...), targetDir, force)
+}
+
+// installOptionsFor is installOptionsForRun for commands that already
+// loaded the config and resolved GitHub auth.
+func installOptionsFor(cfg configFile, gh githubAuth, targetDir string, force bool) (installOptions, error) {
+	pol, err := loadPolicies(cfg)
+	if err != nil {
+		return installOptions{}, err
+	}
+	git, err := gitutil.BackendFor(cfg.GitBackend)
+	if err != nil {
+		return installOptions{}, err
+	}
+	return installOptions{
+		TargetDir:     targetDir,
+		Force:         force,
+		Policy:        pol,
+		RequireSigned: cfg.RequireSigned,
+		Verify: gitutil.VerifyOptions{
+			AllowedSignersFile: strings.TrimSpace(cfg.AllowedSigners),
+			GPGHome:            strings.TrimSpace(cfg.GPGHome),
+		},
+		Tokens: gh.gitTokens(),
+		Git:    git,
+	}, nil
+}
+
+// installContextForRun returns the context for an install: cancelled on
+// Ctrl+C / SIGINT and, when timeout (or the configured install_timeout) is
+// set, after that long.
+func installContextForRun(timeout string) (context.Context, context.CancelFunc, error) {
+	d, err := installTimeoutForRun(timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := withInstallTimeout(ctx, d)
+	return ctx, func() { cancel(); stop() }, nil
+}
+
+// installTimeoutForRun parses timeout, or the configured install_timeout
+// when it's empty. Zero means no limit.
+func installTimeoutForRun(timeout string) (time.Duration, error) {
+	v := strings.TrimSpace(timeout)
+	if v == "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return 0, err
+		}
+		v = strings.TrimSpace(cfg.InstallTimeout)
+	}
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid install timeout %q (use a duration like \"2m\" or \"1h\")", v)
+	}
+	return d, nil
+}
+
+// withInstallTimeout bounds ctx by d, if set. Start it right before the
+// install so time spent in a selector doesn't count.
+func withInstallTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
 func printInstallTip(ctx installDirContext, targetDir string) {
 	if !ctx.UsedFlag {
 		return
@@ -455,3 +855,15 @@ func compactPath(path string) string {
 	}
 	return abs
 }
+
+// expandUserPath expands a leading "~" to the user's home dir.
+func expandUserPath(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") && !strings.HasPrefix(p, "~\\") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[1:])
+}
@@ -2,11 +2,25 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/httpcache"
+	"github.com/kaofelix/skulls/internal/install"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+	"github.com/kaofelix/skulls/internal/tui"
 )
 
 func captureStdoutStderr(t *testing.T) (*bytes.Buffer, *bytes.Buffer, func()) {
@@ -57,7 +71,15 @@ func useTestConfigPath(t *testing.T) {
 	p := filepath.Join(tmp, "config.json")
 	orig := configPathFunc
 	configPathFunc = func() (string, error) { return p, nil }
-	t.Cleanup(func() { configPathFunc = orig })
+	origPolicy := systemPolicyPathFunc
+	systemPolicyPathFunc = func() (string, error) { return filepath.Join(tmp, "system-policy.json"), nil }
+	origGh := ghAuthToken
+	ghAuthToken = func(string) string { return "" }
+	t.Cleanup(func() {
+		configPathFunc = orig
+		systemPolicyPathFunc = origPolicy
+		ghAuthToken = origGh
+	})
 }
 
 func TestRunAdd_WhenSkillIDOmitted_UsesSelectorAndInstallUIWithOverwrite(t *testing.T) {
@@ -68,7 +90,7 @@ func TestRunAdd_WhenSkillIDOmitted_UsesSelectorAndInstallUIWithOverwrite(t *test
 		runAddInstallUI = origInstallUI
 	})
 
-	runAddSelectFromSource = func(source string) (tuiSearchResult, error) {
+	runAddSelectFromSource = func(_ context.Context, source string, _ installOptions) (tuiSearchResult, error) {
 		if source != "owner/repo" {
 			t.Fatalf("selector source=%q", source)
 		}
@@ -78,9 +100,9 @@ func TestRunAdd_WhenSkillIDOmitted_UsesSelectorAndInstallUIWithOverwrite(t *test
 	var gotTarget string
 	var gotForce bool
 	var gotSkill tuiSkill
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
-		gotTarget = targetDir
-		gotForce = force
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		gotTarget = opts.TargetDir
+		gotForce = opts.Force
 		gotSkill = skill
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
@@ -119,12 +141,12 @@ func TestRunAdd_WhenSelectorCancelled_DoesNotInstall(t *testing.T) {
 		runAddInstallUI = origInstallUI
 	})
 
-	runAddSelectFromSource = func(source string) (tuiSearchResult, error) {
+	runAddSelectFromSource = func(_ context.Context, source string, _ installOptions) (tuiSearchResult, error) {
 		return tuiSearchResult{Selected: false}, nil
 	}
 
 	calledInstall := false
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		calledInstall = true
 		return tuiInstallResult{}, nil
 	}
@@ -154,13 +176,13 @@ func TestRunAdd_WhenDirMissing_UsesConfiguredDir(t *testing.T) {
 		runAddInstallUI = origInstallUI
 	})
 
-	runAddSelectFromSource = func(source string) (tuiSearchResult, error) {
+	runAddSelectFromSource = func(_ context.Context, source string, _ installOptions) (tuiSearchResult, error) {
 		return tuiSearchResult{Selected: true, Skill: tuiSkill{Source: source, SkillID: "chosen-skill"}}, nil
 	}
 
 	gotTarget := ""
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
-		gotTarget = targetDir
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		gotTarget = opts.TargetDir
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
 
@@ -185,13 +207,13 @@ func TestRunAdd_WhenSourceUsesAtShorthand_InstallsDirectlyWithoutSelector(t *tes
 	})
 
 	calledSelect := false
-	runAddSelectFromSource = func(source string) (tuiSearchResult, error) {
+	runAddSelectFromSource = func(_ context.Context, source string, _ installOptions) (tuiSearchResult, error) {
 		calledSelect = true
 		return tuiSearchResult{}, nil
 	}
 
 	var gotSkill tuiSkill
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		gotSkill = skill
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
@@ -222,7 +244,7 @@ func TestRunAdd_WhenSourceIsGitSSH_DoesNotUseAtShorthand(t *testing.T) {
 	})
 
 	calledSelect := false
-	runAddSelectFromSource = func(source string) (tuiSearchResult, error) {
+	runAddSelectFromSource = func(_ context.Context, source string, _ installOptions) (tuiSearchResult, error) {
 		calledSelect = true
 		if source != "git@github.com:owner/repo" {
 			t.Fatalf("selector source=%q", source)
@@ -231,7 +253,7 @@ func TestRunAdd_WhenSourceIsGitSSH_DoesNotUseAtShorthand(t *testing.T) {
 	}
 
 	calledInstall := false
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		calledInstall = true
 		return tuiInstallResult{}, nil
 	}
@@ -258,15 +280,15 @@ func TestRunAdd_WhenInstallUICannotOpenTTY_FallsBackToPlainInstall(t *testing.T)
 		runAddInstallPlain = origInstallPlain
 	})
 
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		return tuiInstallResult{}, errNoTTYForTest{}
 	}
 
 	calledPlain := false
-	runAddInstallPlain = func(source string, skillID string, targetDir string, force bool) (string, error) {
+	runAddInstallPlain = func(_ context.Context, source string, skillID string, opts installOptions) (string, error) {
 		calledPlain = true
-		if source != "owner/repo" || skillID != "my-skill" || targetDir != "/tmp/skills" || !force {
-			t.Fatalf("unexpected plain args: source=%q skill=%q dir=%q force=%v", source, skillID, targetDir, force)
+		if source != "owner/repo" || skillID != "my-skill" || opts.TargetDir != "/tmp/skills" || !opts.Force {
+			t.Fatalf("unexpected plain args: source=%q skill=%q dir=%q force=%v", source, skillID, opts.TargetDir, opts.Force)
 		}
 		return "/tmp/installed", nil
 	}
@@ -297,13 +319,13 @@ func TestRunAdd_WhenSkillIDProvided_StillInstallsDirectly(t *testing.T) {
 	})
 
 	calledSelect := false
-	runAddSelectFromSource = func(source string) (tuiSearchResult, error) {
+	runAddSelectFromSource = func(_ context.Context, source string, _ installOptions) (tuiSearchResult, error) {
 		calledSelect = true
 		return tuiSearchResult{}, nil
 	}
 
 	var gotSkill tuiSkill
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		gotSkill = skill
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
@@ -371,8 +393,8 @@ func TestRunAdd_DirFlagOverridesConfiguredDir(t *testing.T) {
 	t.Cleanup(func() { runAddInstallUI = origInstallUI })
 
 	gotTarget := ""
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
-		gotTarget = targetDir
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		gotTarget = opts.TargetDir
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
 
@@ -387,13 +409,124 @@ func TestRunAdd_DirFlagOverridesConfiguredDir(t *testing.T) {
 	}
 }
 
+func TestRunAdd_SeveralSkillsInstallAsBatch(t *testing.T) {
+	useTestConfigPath(t)
+	origBatchUI := runAddBatchUI
+	origInstallUI := runAddInstallUI
+	t.Cleanup(func() {
+		runAddBatchUI = origBatchUI
+		runAddInstallUI = origInstallUI
+	})
+	runAddInstallUI = func(context.Context, tuiSkill, installOptions) (tuiInstallResult, error) {
+		t.Fatal("single install UI used for a batch")
+		return tuiInstallResult{}, nil
+	}
+
+	var gotItems []install.BatchItem
+	var gotOpts batchOptions
+	runAddBatchUI = func(_ context.Context, items []install.BatchItem, opts batchOptions) ([]batchResult, error) {
+		gotItems = items
+		gotOpts = opts
+		return []batchResult{
+			{Item: items[0], InstalledPath: "/tmp/skills/alpha"},
+			{Item: items[1], Err: errors.New("skill not found")},
+		}, nil
+	}
+
+	outBuf, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"add", "owner/repo@alpha", "other/repo@beta", "--dir", "/tmp/skills", "--jobs", "2"})
+	restore()
+	if exit != 1 {
+		t.Fatalf("expected a failed item to fail the run, exit=%d stderr=%s", exit, errBuf.String())
+	}
+	want := []install.BatchItem{{Source: "owner/repo", SkillID: "alpha"}, {Source: "other/repo", SkillID: "beta"}}
+	if fmt.Sprint(gotItems) != fmt.Sprint(want) {
+		t.Fatalf("items=%+v", gotItems)
+	}
+	if gotOpts.Jobs != 2 || gotOpts.TargetDir != "/tmp/skills" || !gotOpts.Force {
+		t.Fatalf("opts=%+v", gotOpts)
+	}
+	out := outBuf.String()
+	if !strings.Contains(out, "owner/repo@alpha → /tmp/skills/alpha") || !strings.Contains(out, "✗ other/repo@beta: skill not found") || !strings.Contains(out, "1 installed, 1 failed") {
+		t.Fatalf("unexpected report: %q", out)
+	}
+
+	runAddBatchUI = func(_ context.Context, items []install.BatchItem, _ batchOptions) ([]batchResult, error) {
+		gotItems = items
+		return []batchResult{{Item: items[0]}, {Item: items[1]}, {Item: items[2]}}, nil
+	}
+	_, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"add", "owner/repo", "a", "b", "c", "--dir", "/tmp/skills", "--ref", "v1"})
+	restore()
+	if exit != 0 || len(gotItems) != 3 || gotItems[2].Source != "owner/repo" || gotItems[2].SkillID != "c" || gotItems[2].Ref != "v1" {
+		t.Fatalf("exit=%d items=%+v stderr=%s", exit, gotItems, errBuf.String())
+	}
+
+	for _, tc := range []struct {
+		args []string
+		flag string
+	}{
+		{[]string{"add", "owner/repo", "a", "b", "--path", "skills/a"}, "--path"},
+		{[]string{"add", "owner/repo@a", "other/repo@b", "--ref", "v1"}, "--ref"},
+		{[]string{"add", "owner/repo", "a", "--jobs", "2"}, "--jobs"},
+	} {
+		_, errBuf, restore = captureStdoutStderr(t)
+		exit = Run(append(tc.args, "--dir", "/tmp/skills"))
+		restore()
+		if exit != 2 || !strings.Contains(errBuf.String(), tc.flag) {
+			t.Fatalf("%v: exit=%d stderr=%s", tc.args, exit, errBuf.String())
+		}
+	}
+}
+
+func TestRunAdd_TimeoutBoundsInstallContext(t *testing.T) {
+	useTestConfigPath(t)
+	if err := saveConfig(configFile{InstallTimeout: "1h"}); err != nil {
+		t.Fatal(err)
+	}
+
+	origInstallUI := runAddInstallUI
+	t.Cleanup(func() { runAddInstallUI = origInstallUI })
+
+	var remaining time.Duration
+	runAddInstallUI = func(ctx context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatalf("expected a deadline")
+		}
+		remaining = time.Until(deadline)
+		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"add", "owner/repo", "my-skill", "--dir", "/tmp/skills"})
+	restore()
+	if exit != 0 || remaining <= 50*time.Minute {
+		t.Fatalf("configured timeout: exit=%d remaining=%v stderr=%s", exit, remaining, errBuf.String())
+	}
+
+	_, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"add", "owner/repo", "my-skill", "--dir", "/tmp/skills", "--timeout=30s"})
+	restore()
+	if exit != 0 || remaining > 30*time.Second {
+		t.Fatalf("flag timeout: exit=%d remaining=%v stderr=%s", exit, remaining, errBuf.String())
+	}
+
+	_, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"add", "owner/repo", "my-skill", "--dir", "/tmp/skills", "--timeout", "soon"})
+	restore()
+	if exit != 2 || !strings.Contains(errBuf.String(), "invalid install timeout") {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+}
+
 func TestRunAdd_WhenNoConfigAndDirFlag_ShowsTipToPersistDir(t *testing.T) {
 	useTestConfigPath(t)
 
 	origInstallUI := runAddInstallUI
 	t.Cleanup(func() { runAddInstallUI = origInstallUI })
 
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
 
@@ -422,7 +555,7 @@ func TestRunAdd_WhenConfiguredAndDirFlagDiffers_ShowsOverrideTip(t *testing.T) {
 	origInstallUI := runAddInstallUI
 	t.Cleanup(func() { runAddInstallUI = origInstallUI })
 
-	runAddInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
 
@@ -444,6 +577,41 @@ func TestRunAdd_WhenConfiguredAndDirFlagDiffers_ShowsOverrideTip(t *testing.T) {
 	}
 }
 
+func TestRunAdd_TimeoutStartsAfterSelector(t *testing.T) {
+	useTestConfigPath(t)
+
+	origSelect := runAddSelectFromSource
+	origInstallUI := runAddInstallUI
+	t.Cleanup(func() {
+		runAddSelectFromSource = origSelect
+		runAddInstallUI = origInstallUI
+	})
+
+	runAddSelectFromSource = func(ctx context.Context, source string, opts installOptions) (tuiSearchResult, error) {
+		// The user takes longer to pick than the install may take.
+		time.Sleep(150 * time.Millisecond)
+		if err := ctx.Err(); err != nil {
+			t.Errorf("selector context: %v", err)
+		}
+		return tuiSearchResult{Selected: true, Skill: tuiSkill{SkillID: "slow-pick"}}, nil
+	}
+	var installErr error
+	runAddInstallUI = func(ctx context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		installErr = ctx.Err()
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("expected the install to have a deadline")
+		}
+		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"add", "owner/repo", "--dir", "/tmp/skills", "--timeout", "100ms"})
+	restore()
+	if exit != 0 || installErr != nil {
+		t.Fatalf("exit=%d installErr=%v stderr=%s", exit, installErr, errBuf.String())
+	}
+}
+
 func TestRunSearch_DirFlagOverridesConfiguredDir(t *testing.T) {
 	useTestConfigPath(t)
 
@@ -458,13 +626,13 @@ func TestRunSearch_DirFlagOverridesConfiguredDir(t *testing.T) {
 		runSearchInstallUI = origInstall
 	})
 
-	runSearchUI = func() (tuiSearchResult, error) {
+	runSearchUI = func(tuiSearchOptions) (tuiSearchResult, error) {
 		return tuiSearchResult{Selected: true, Skill: tuiSkill{Source: "owner/repo", SkillID: "chosen-skill"}}, nil
 	}
 
 	gotTarget := ""
-	runSearchInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
-		gotTarget = targetDir
+	runSearchInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		gotTarget = opts.TargetDir
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
 
@@ -479,6 +647,122 @@ func TestRunSearch_DirFlagOverridesConfiguredDir(t *testing.T) {
 	}
 }
 
+func TestRunSearch_PassesConfiguredLimitsAndLimitFlagOverrides(t *testing.T) {
+	useTestConfigPath(t)
+	if err := saveConfig(configFile{Dir: t.TempDir(), SearchLimit: 25, PopularLimit: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	origSearch := runSearchUI
+	t.Cleanup(func() { runSearchUI = origSearch })
+
+	var got tuiSearchOptions
+	runSearchUI = func(opts tuiSearchOptions) (tuiSearchResult, error) {
+		got = opts
+		return tuiSearchResult{}, nil
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"--no-cache"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	if got.SearchLimit != 25 || got.PopularLimit != 100 {
+		t.Fatalf("limits=%d/%d", got.SearchLimit, got.PopularLimit)
+	}
+
+	_, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"--no-cache", "--limit=30"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	if got.SearchLimit != 30 || got.PopularLimit != 30 {
+		t.Fatalf("limits=%d/%d", got.SearchLimit, got.PopularLimit)
+	}
+
+	_, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"--limit", "0"})
+	restore()
+	if exit != 2 || !strings.Contains(errBuf.String(), "--limit must be a positive number") {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+}
+
+func TestRunSearch_ResolvesGitHubAuthOnce(t *testing.T) {
+	useTestConfigPath(t)
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	if err := saveConfig(configFile{Dir: t.TempDir(), GitHubHosts: []githubHostConfig{{Host: "ghe.example.com"}}}); err != nil {
+		t.Fatal(err)
+	}
+	calls := map[string]int{}
+	ghAuthToken = func(host string) string {
+		calls[host]++
+		return "gh-" + host
+	}
+	origSearch := runSearchUI
+	t.Cleanup(func() { runSearchUI = origSearch })
+	runSearchUI = func(tuiSearchOptions) (tuiSearchResult, error) { return tuiSearchResult{}, nil }
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"--no-cache", "--catalog", filepath.Join(t.TempDir(), "index.json")})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	if calls["github.com"] != 1 || calls["ghe.example.com"] != 1 {
+		t.Fatalf("gh auth token calls=%v", calls)
+	}
+}
+
+func TestRunSearch_InstalledTabActionsUseRecordedInstall(t *testing.T) {
+	useTestConfigPath(t)
+
+	origSearch := runSearchUI
+	origInstall := runSearchInstallUI
+	t.Cleanup(func() {
+		runSearchUI = origSearch
+		runSearchInstallUI = origInstall
+	})
+
+	md := &install.Metadata{SkillID: "demo", Source: "owner/repo", Ref: "main", Commit: "0123456789abcdef0123456789abcdef01234567", Path: "skills/demo"}
+	var action tui.Action
+	runSearchUI = func(opts tuiSearchOptions) (tuiSearchResult, error) {
+		if opts.TargetDir != "/tmp/skills" || opts.CheckUpdate == nil {
+			t.Fatalf("search options=%+v", opts)
+		}
+		return tuiSearchResult{Selected: true, Skill: tuiSkill{SkillID: "demo"}, Action: action, Installed: &install.InstalledSkill{Folder: "demo", Metadata: md}}, nil
+	}
+	var gotSkill tuiSkill
+	var gotOpts installOptions
+	runSearchInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		gotSkill = skill
+		gotOpts = opts
+		return tuiInstallResult{InstalledPath: "/tmp/skills/demo"}, nil
+	}
+
+	for _, tc := range []struct {
+		action tui.Action
+		ref    string
+	}{
+		{tui.ActionUpdate, "main"},
+		{tui.ActionReinstall, md.Commit},
+	} {
+		action = tc.action
+		_, errBuf, restore := captureStdoutStderr(t)
+		exit := Run([]string{"--dir", "/tmp/skills"})
+		restore()
+		if exit != 0 {
+			t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+		}
+		if gotSkill.Source != "owner/repo" || gotSkill.SkillID != "demo" || gotOpts.Ref != tc.ref || gotOpts.Path != "skills/demo" || !gotOpts.Force {
+			t.Fatalf("action %d: skill=%+v ref=%q path=%q force=%v", tc.action, gotSkill, gotOpts.Ref, gotOpts.Path, gotOpts.Force)
+		}
+	}
+}
+
 func TestRunSearch_WhenNoConfigAndDirFlag_ShowsTipToPersistDir(t *testing.T) {
 	useTestConfigPath(t)
 
@@ -489,10 +773,10 @@ func TestRunSearch_WhenNoConfigAndDirFlag_ShowsTipToPersistDir(t *testing.T) {
 		runSearchInstallUI = origInstall
 	})
 
-	runSearchUI = func() (tuiSearchResult, error) {
+	runSearchUI = func(tuiSearchOptions) (tuiSearchResult, error) {
 		return tuiSearchResult{Selected: true, Skill: tuiSkill{Source: "owner/repo", SkillID: "chosen-skill"}}, nil
 	}
-	runSearchInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runSearchInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
 	}
 
@@ -518,10 +802,10 @@ func TestRunSearch_FinalSuccessLine_ShowsSourceAndHomeShortenedPath(t *testing.T
 	home := t.TempDir()
 	t.Setenv("HOME", home)
 
-	runSearchUI = func() (tuiSearchResult, error) {
+	runSearchUI = func(tuiSearchOptions) (tuiSearchResult, error) {
 		return tuiSearchResult{Selected: true, Skill: tuiSkill{Source: "owner/repo", SkillID: "chosen-skill"}}, nil
 	}
-	runSearchInstallUI = func(targetDir string, force bool, skill tuiSkill) (tuiInstallResult, error) {
+	runSearchInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
 		return tuiInstallResult{InstalledPath: filepath.Join(home, ".pi/agent/skills/chosen-skill")}, nil
 	}
 
@@ -542,3 +826,745 @@ func TestRunSearch_FinalSuccessLine_ShowsSourceAndHomeShortenedPath(t *testing.T
 		t.Fatalf("missing home-shortened path in success output: %q", out)
 	}
 }
+
+func TestRunAdd_PassesSystemAndConfigPolicyToInstall(t *testing.T) {
+	useTestConfigPath(t)
+
+	sysPath, err := systemPolicyPathFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sysPath, []byte(`{"allow":[{"host":"github.com"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath, err := configPathFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, []byte(`{"dir":"/tmp/skills","policy":{"deny":[{"owner":"evil"}]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origInstallUI := runAddInstallUI
+	t.Cleanup(func() { runAddInstallUI = origInstallUI })
+
+	var gotOpts installOptions
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		gotOpts = opts
+		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"add", "owner/repo", "my-skill"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	if len(gotOpts.Policy) != 2 {
+		t.Fatalf("expected system and config policies, got %+v", gotOpts.Policy)
+	}
+	if err := gotOpts.Policy.Check("evil/repo"); err == nil {
+		t.Fatalf("expected config deny rule to apply")
+	}
+	if err := gotOpts.Policy.Check("https://gitlab.com/owner/repo.git"); err == nil {
+		t.Fatalf("expected system allow rule to apply")
+	}
+	if err := gotOpts.Policy.Check("owner/repo"); err != nil {
+		t.Fatalf("expected owner/repo to be allowed, got %v", err)
+	}
+}
+
+func TestSetInstallDir_PreservesOtherConfig(t *testing.T) {
+	useTestConfigPath(t)
+
+	cfgPath, err := configPathFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, []byte(`{"dir":"/old","policy":{"deny":[{"owner":"evil"}]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := setInstallDir("/new"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Dir != "/new" {
+		t.Fatalf("dir=%q", cfg.Dir)
+	}
+	if cfg.Policy == nil || len(cfg.Policy.Deny) != 1 {
+		t.Fatalf("expected policy to be preserved, got %+v", cfg.Policy)
+	}
+}
+
+func TestRunAdd_PassesRefAndRequireSigned(t *testing.T) {
+	useTestConfigPath(t)
+
+	cfgPath, err := configPathFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, []byte(`{"allowed_signers":"~/.config/skulls/allowed_signers"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origInstallUI := runAddInstallUI
+	t.Cleanup(func() { runAddInstallUI = origInstallUI })
+
+	var gotOpts installOptions
+	runAddInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		gotOpts = opts
+		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"add", "owner/repo", "my-skill", "--dir", "/tmp/skills", "--ref=v1.2.0", "--path", "skills/my-skill", "--require-signed"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	if gotOpts.Ref != "v1.2.0" {
+		t.Fatalf("ref=%q", gotOpts.Ref)
+	}
+	if gotOpts.Path != "skills/my-skill" {
+		t.Fatalf("path=%q", gotOpts.Path)
+	}
+	if !gotOpts.RequireSigned {
+		t.Fatalf("expected require-signed")
+	}
+	if gotOpts.Verify.AllowedSignersFile != "~/.config/skulls/allowed_signers" {
+		t.Fatalf("allowed signers=%q", gotOpts.Verify.AllowedSignersFile)
+	}
+}
+
+func TestRunVerify_ReportsTamperedSkill(t *testing.T) {
+	useTestConfigPath(t)
+
+	target := t.TempDir()
+	skillDir := filepath.Join(target, "demo")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := install.DigestDir(skillDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := install.Metadata{SkillID: "demo", Source: "owner/repo", Digest: digest.Sum, Files: digest.Files}
+	b, err := json.Marshal(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(target, install.MetadataDirName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, install.MetadataDirName, "demo.json"), b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outBuf, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"verify", "--dir", target})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stdout=%s stderr=%s", exit, outBuf.String(), errBuf.String())
+	}
+	if !strings.Contains(outBuf.String(), "✓ demo") {
+		t.Fatalf("expected clean verify, got %q", outBuf.String())
+	}
+
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origRestore := runVerifyRestore
+	t.Cleanup(func() { runVerifyRestore = origRestore })
+	var restored install.Metadata
+	runVerifyRestore = func(_ context.Context, md install.Metadata, opts installOptions) (string, error) {
+		restored = md
+		return skillDir, nil
+	}
+
+	outBuf, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"verify", "demo", "--dir", target})
+	restore()
+	if exit != 1 {
+		t.Fatalf("exit=%d stdout=%s stderr=%s", exit, outBuf.String(), errBuf.String())
+	}
+	if !strings.Contains(outBuf.String(), "M SKILL.md") {
+		t.Fatalf("expected modified file listing, got %q", outBuf.String())
+	}
+
+	outBuf, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"verify", "demo", "--dir", target, "--restore"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stdout=%s stderr=%s", exit, outBuf.String(), errBuf.String())
+	}
+	if restored.SkillID != "demo" {
+		t.Fatalf("expected restore of demo, got %+v", restored)
+	}
+}
+
+func TestRunDiff_PrintsChangesAgainstUpstream(t *testing.T) {
+	useTestConfigPath(t)
+
+	target := t.TempDir()
+	skillDir := filepath.Join(target, "demo")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(install.Metadata{SkillID: "demo", Source: "owner/repo", Ref: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(target, install.MetadataDirName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, install.MetadataDirName, "demo.json"), b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	upstream := t.TempDir()
+	if err := os.WriteFile(filepath.Join(upstream, "SKILL.md"), []byte("one\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origFetch := runDiffFetch
+	t.Cleanup(func() { runDiffFetch = origFetch })
+	var gotSource, gotRef string
+	runDiffFetch = func(_ context.Context, source string, skillID string, opts installOptions) (string, func(), error) {
+		gotSource = source
+		gotRef = opts.Ref
+		return upstream, func() {}, nil
+	}
+
+	outBuf, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"diff", "demo", "--dir", target, "--ref", "v2"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stdout=%s stderr=%s", exit, outBuf.String(), errBuf.String())
+	}
+	if gotSource != "owner/repo" || gotRef != "v2" {
+		t.Fatalf("fetched source=%q ref=%q", gotSource, gotRef)
+	}
+	want := "--- a/SKILL.md\n+++ b/SKILL.md\n@@ -1,2 +1,2 @@\n one\n-two\n+three\n"
+	if outBuf.String() != want {
+		t.Fatalf("unexpected diff:\n%s", outBuf.String())
+	}
+}
+
+func useTestDoctorEnv(t *testing.T, endpoints []doctorEndpoint) string {
+	t.Helper()
+	tmp := t.TempDir()
+	origTemp := doctorTempDir
+	origEndpoints := doctorEndpoints
+	doctorTempDir = func() string { return tmp }
+	doctorEndpoints = func(configFile) []doctorEndpoint { return endpoints }
+	t.Cleanup(func() {
+		doctorTempDir = origTemp
+		doctorEndpoints = origEndpoints
+	})
+	return tmp
+}
+
+func TestDefaultDoctorEndpoints_IncludesGitHubHosts(t *testing.T) {
+	eps := defaultDoctorEndpoints(configFile{GitHubHosts: []githubHostConfig{
+		{Host: "GHE.example.com"},
+		{Host: "git.corp", APIBase: "https://api.git.corp/"},
+	}})
+
+	var got []string
+	for _, ep := range eps[3:] {
+		got = append(got, ep.Name+"="+ep.URL)
+	}
+	want := "ghe.example.com API=https://ghe.example.com/api/v3 git.corp API=https://api.git.corp"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("endpoints=%v", got)
+	}
+}
+
+func TestRunDoctor_ReportsProblemsWithFixes(t *testing.T) {
+	useTestConfigPath(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+	tmp := useTestDoctorEnv(t, []doctorEndpoint{{Name: "registry", URL: srv.URL}})
+
+	target := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(target, "broken"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(target, install.MetadataDirName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, install.MetadataDirName, "gone.json"), []byte(`{"skill_id":"gone"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(tmp, "skulls-123")
+	if err := os.MkdirAll(stale, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	staging := filepath.Join(target, ".skulls-staging-456")
+	if err := os.MkdirAll(filepath.Join(staging, ".previous"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fresh := filepath.Join(target, ".skulls-staging-789")
+	if err := os.MkdirAll(fresh, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleTempAge)
+	for _, dir := range []string{stale, staging} {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outBuf, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"doctor", "--dir", target})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stdout=%s stderr=%s", exit, outBuf.String(), errBuf.String())
+	}
+	out := outBuf.String()
+	for _, want := range []string{
+		"✓ git: version",
+		"✓ target dir:",
+		"! skill broken: SKILL.md is missing",
+		"! metadata gone: install metadata without a skill folder",
+		"✓ registry: " + srv.URL + " reachable",
+		"! temp dirs: 2 stale",
+		"rm -rf",
+		strconv.Quote(staging),
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, fresh) {
+		t.Fatalf("running install's staging dir reported:\n%s", out)
+	}
+}
+
+func TestRunDoctor_FailsOnInvalidConfig(t *testing.T) {
+	useTestConfigPath(t)
+	useTestDoctorEnv(t, nil)
+	p, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outBuf, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"doctor"})
+	restore()
+	if exit != 1 {
+		t.Fatalf("exit=%d stdout=%s stderr=%s", exit, outBuf.String(), errBuf.String())
+	}
+	if !strings.Contains(outBuf.String(), "✗ config:") || !strings.Contains(outBuf.String(), "Fix the JSON") {
+		t.Fatalf("expected config failure with fix, got:\n%s", outBuf.String())
+	}
+}
+
+func TestRunSearch_MergesConfiguredRegistries(t *testing.T) {
+	useTestConfigPath(t)
+	if err := saveConfig(configFile{Registries: []registryConfig{
+		{Name: "internal", Type: "http", URL: "https://skills.internal.example"},
+		{Name: "team", Type: "index", URL: "/srv/skills/index.json"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	origSearch := runSearchUI
+	t.Cleanup(func() { runSearchUI = origSearch })
+
+	var got skillsapi.Registry
+	runSearchUI = func(opts tuiSearchOptions) (tuiSearchResult, error) {
+		got = opts.Registry
+		return tuiSearchResult{}, nil
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"--dir", t.TempDir()})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	multi, ok := got.(skillsapi.Multi)
+	if !ok {
+		t.Fatalf("expected merged registries, got %T", got)
+	}
+	if multi.Name() != "skills.sh, internal, team" {
+		t.Fatalf("registries=%q", multi.Name())
+	}
+}
+
+func TestRunSearch_RejectsUnknownRegistryType(t *testing.T) {
+	useTestConfigPath(t)
+	if err := saveConfig(configFile{Registries: []registryConfig{{Name: "x", Type: "ftp", URL: "ftp://x"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"--dir", t.TempDir()})
+	restore()
+	if exit != 2 || !strings.Contains(errBuf.String(), "unknown type") {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+}
+
+func TestRunIndexBuild_WritesIndexFromSources(t *testing.T) {
+	useTestConfigPath(t)
+
+	repo := t.TempDir()
+	files := map[string]string{
+		"skills/alpha/SKILL.md": "---\nname: alpha\ndescription: First skill\ntags: [git, review]\n---\n",
+		"skills/beta/SKILL.md":  "---\nname: beta\ndescription: Second skill\n---\n",
+	}
+	for rel, body := range files {
+		p := filepath.Join(repo, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "index.json")
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"index", "build", repo, "-o", out})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := skillsapi.ParseIndex(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Version != skillsapi.IndexVersion || index.GeneratedAt.IsZero() {
+		t.Fatalf("unexpected header: %+v", index)
+	}
+	if len(index.Skills) != 2 {
+		t.Fatalf("skills=%+v", index.Skills)
+	}
+	alpha := index.Skills[0]
+	if alpha.SkillID != "alpha" || alpha.Description != "First skill" || alpha.Path != "skills/alpha" || alpha.Source != repo {
+		t.Fatalf("alpha=%+v", alpha)
+	}
+	if strings.Join(alpha.Tags, ",") != "git,review" {
+		t.Fatalf("tags=%v", alpha.Tags)
+	}
+}
+
+func TestRunSearch_CatalogFlagUsesIndexAndRef(t *testing.T) {
+	useTestConfigPath(t)
+
+	origSearch := runSearchUI
+	origInstall := runSearchInstallUI
+	t.Cleanup(func() {
+		runSearchUI = origSearch
+		runSearchInstallUI = origInstall
+	})
+
+	var gotRegistry skillsapi.Registry
+	runSearchUI = func(opts tuiSearchOptions) (tuiSearchResult, error) {
+		gotRegistry = opts.Registry
+		return tuiSearchResult{Selected: true, Skill: tuiSkill{Source: "team/skills", SkillID: "alpha", Ref: "v2"}}, nil
+	}
+	var gotRef string
+	runSearchInstallUI = func(_ context.Context, skill tuiSkill, opts installOptions) (tuiInstallResult, error) {
+		gotRef = opts.Ref
+		return tuiInstallResult{InstalledPath: "/tmp/installed"}, nil
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"--dir", t.TempDir(), "--catalog", "/shared/skills/index.json"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	index, ok := gotRegistry.(*skillsapi.Index)
+	if !ok || index.Location != "/shared/skills/index.json" {
+		t.Fatalf("registry=%#v", gotRegistry)
+	}
+	if gotRef != "v2" {
+		t.Fatalf("ref=%q", gotRef)
+	}
+}
+
+func useTestCacheDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	orig := cacheDirFunc
+	cacheDirFunc = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { cacheDirFunc = orig })
+	return dir
+}
+
+func TestRunCatalogPull_ThenSearchOffline(t *testing.T) {
+	useTestConfigPath(t)
+	cache := useTestCacheDir(t)
+
+	origPull := pullCatalogSnapshot
+	origSearch := runSearchUI
+	t.Cleanup(func() {
+		pullCatalogSnapshot = origPull
+		runSearchUI = origSearch
+	})
+
+	var gotOpts skillsapi.PullOptions
+	pullCatalogSnapshot = func(_ context.Context, _ skillsapi.Registry, opts skillsapi.PullOptions) (skillsapi.Snapshot, error) {
+		gotOpts = opts
+		return skillsapi.Snapshot{
+			Version:  skillsapi.SnapshotVersion,
+			PulledAt: time.Now().Add(-time.Hour),
+			Skills:   []skillsapi.SnapshotSkill{{Skill: skillsapi.Skill{SkillID: "a", Source: "owner/repo"}, Body: "# a"}},
+		}, nil
+	}
+
+	outBuf, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"catalog", "pull", "--bodies", "--limit", "20", "--query", "git"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	if !gotOpts.Bodies || gotOpts.Limit != 20 || len(gotOpts.Queries) != 1 {
+		t.Fatalf("pull opts=%+v", gotOpts)
+	}
+	if !strings.Contains(outBuf.String(), "Saved 1 skill(s) (1 with SKILL.md)") {
+		t.Fatalf("stdout=%q", outBuf.String())
+	}
+	if _, err := os.Stat(filepath.Join(cache, "catalog.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotSearch tuiSearchOptions
+	runSearchUI = func(opts tuiSearchOptions) (tuiSearchResult, error) {
+		gotSearch = opts
+		return tuiSearchResult{}, nil
+	}
+	_, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"--dir", t.TempDir(), "--offline"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	snap, ok := gotSearch.Registry.(skillsapi.Snapshot)
+	if !ok || len(snap.Skills) != 1 {
+		t.Fatalf("registry=%#v", gotSearch.Registry)
+	}
+	if gotSearch.SnapshotAt.IsZero() {
+		t.Fatalf("expected snapshot time to be passed to the UI")
+	}
+}
+
+func TestRunSearch_OfflineWithoutSnapshotExplainsHowToPull(t *testing.T) {
+	useTestConfigPath(t)
+	useTestCacheDir(t)
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"--dir", t.TempDir(), "--offline"})
+	restore()
+	if exit != 1 || !strings.Contains(errBuf.String(), "skulls catalog pull") {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+}
+
+func TestRunSearch_UsesHTTPCacheUnlessDisabled(t *testing.T) {
+	useTestConfigPath(t)
+	cache := useTestCacheDir(t)
+	if err := saveConfig(configFile{CacheTTL: "15m"}); err != nil {
+		t.Fatal(err)
+	}
+
+	origSearch := runSearchUI
+	t.Cleanup(func() { runSearchUI = origSearch })
+	var got skillsapi.Registry
+	runSearchUI = func(opts tuiSearchOptions) (tuiSearchResult, error) {
+		got = opts.Registry
+		return tuiSearchResult{}, nil
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"--dir", t.TempDir()})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	client, ok := got.(skillsapi.Client)
+	if !ok || client.HTTP == nil {
+		t.Fatalf("registry=%#v", got)
+	}
+	tr, ok := client.HTTP.Transport.(*httpcache.Transport)
+	if !ok {
+		t.Fatalf("expected cached transport, got %T", client.HTTP.Transport)
+	}
+	if tr.TTL != 15*time.Minute || tr.Dir != filepath.Join(cache, "http") {
+		t.Fatalf("transport=%+v", tr)
+	}
+
+	_, errBuf, restore = captureStdoutStderr(t)
+	exit = Run([]string{"--dir", t.TempDir(), "--no-cache"})
+	restore()
+	if exit != 0 {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+	if _, cached := got.(skillsapi.Client).HTTP.Transport.(*httpcache.Transport); cached {
+		t.Fatalf("expected --no-cache to bypass the cache")
+	}
+}
+
+func TestRunSearch_RejectsInvalidCacheTTL(t *testing.T) {
+	useTestConfigPath(t)
+	useTestCacheDir(t)
+	if err := saveConfig(configFile{CacheTTL: "soon"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, errBuf, restore := captureStdoutStderr(t)
+	exit := Run([]string{"--dir", t.TempDir()})
+	restore()
+	if exit != 2 || !strings.Contains(errBuf.String(), "cache_ttl") {
+		t.Fatalf("exit=%d stderr=%s", exit, errBuf.String())
+	}
+}
+
+func TestGitHubTokenForRun_PrefersEnvThenConfigThenGh(t *testing.T) {
+	useTestConfigPath(t)
+	ghAuthToken = func(string) string { return "from-gh" }
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	if got := githubTokenForRun(configFile{}); got != "from-gh" {
+		t.Fatalf("got %q want gh token", got)
+	}
+	if got := githubTokenForRun(configFile{GitHubToken: "from-config"}); got != "from-config" {
+		t.Fatalf("got %q want config token", got)
+	}
+	t.Setenv("GH_TOKEN", "from-gh-env")
+	if got := githubTokenForRun(configFile{GitHubToken: "from-config"}); got != "from-gh-env" {
+		t.Fatalf("got %q want GH_TOKEN", got)
+	}
+	t.Setenv("GITHUB_TOKEN", "from-github-env")
+	if got := githubTokenForRun(configFile{GitHubToken: "from-config"}); got != "from-github-env" {
+		t.Fatalf("got %q want GITHUB_TOKEN", got)
+	}
+
+	reg, err := registryForRun(configFile{}, nil, githubAuthForRun(configFile{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := reg.(skillsapi.Client); !ok || c.GitHubToken != "from-github-env" {
+		t.Fatalf("registry=%#v", reg)
+	}
+	opts, err := installOptionsForRun(t.TempDir(), false, "owner/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Tokens) != 1 || opts.Tokens[0] != (gitutil.Token{Host: "github.com", Value: "from-github-env"}) {
+		t.Fatalf("tokens=%+v", opts.Tokens)
+	}
+}
+
+func TestGitHubAuthForRun_EnterpriseHosts(t *testing.T) {
+	useTestConfigPath(t)
+	t.Setenv("GITHUB_TOKEN", "public")
+	ghAuthToken = func(host string) string { return "gh-" + host }
+	if err := saveConfig(configFile{GitHubHosts: []githubHostConfig{
+		{Host: "GitHub.Acme.com", APIBase: "https://github.acme.com/api/v3"},
+		{Host: "ghe.other.io", Token: "configured"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := installOptionsForRun(t.TempDir(), false, "owner/repo", "github.acme.com/team/skills", "https://ghe.other.io/team/skills.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []gitutil.Token{
+		{Host: "github.com", Value: "public"},
+		{Host: "github.acme.com", Value: "gh-github.acme.com"},
+		{Host: "ghe.other.io", Value: "configured"},
+	}
+	if len(opts.Tokens) != len(want) {
+		t.Fatalf("tokens=%+v", opts.Tokens)
+	}
+	for i := range want {
+		if opts.Tokens[i] != want[i] {
+			t.Fatalf("tokens=%+v", opts.Tokens)
+		}
+	}
+
+	if src, skill, ok := splitSourceSkillShorthand("github.acme.com/team/skills@lint"); !ok || src != "github.acme.com/team/skills" || skill != "lint" {
+		t.Fatalf("shorthand: src=%q skill=%q ok=%v", src, skill, ok)
+	}
+	if u, err := gitutil.NormalizeSourceToGitURL("github.acme.com/team/skills"); err != nil || u != "https://github.acme.com/team/skills.git" {
+		t.Fatalf("normalize: %q %v", u, err)
+	}
+}
+
+func TestInstallOptionsForRun_ResolvesTokensOnlyForSourceHosts(t *testing.T) {
+	useTestConfigPath(t)
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	if err := saveConfig(configFile{GitHubHosts: []githubHostConfig{{Host: "ghe.example.com"}}}); err != nil {
+		t.Fatal(err)
+	}
+	calls := map[string]int{}
+	ghAuthToken = func(host string) string {
+		calls[host]++
+		return "gh-" + host
+	}
+
+	opts, err := installOptionsForRun(t.TempDir(), false, "./local/skills", "https://gitlab.com/team/skills", "https://github.com/o/r/archive/main.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 0 || len(opts.Tokens) != 0 {
+		t.Fatalf("calls=%v tokens=%+v", calls, opts.Tokens)
+	}
+
+	opts, err = installOptionsForRun(t.TempDir(), false, "ghe.example.com/team/skills")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls["ghe.example.com"] != 1 {
+		t.Fatalf("calls=%v", calls)
+	}
+	if len(opts.Tokens) != 1 || opts.Tokens[0] != (gitutil.Token{Host: "ghe.example.com", Value: "gh-ghe.example.com"}) {
+		t.Fatalf("tokens=%+v", opts.Tokens)
+	}
+}
+
+func TestPlainCloneProgress_PrintsQuarterMilestones(t *testing.T) {
+	var buf bytes.Buffer
+	report := plainCloneProgress(&buf)
+	report(install.Event{Step: install.StepClone, Message: "Cloning repository (full)"})
+	for _, pct := range []int{1, 10, 26, 30, 60, 99, 100} {
+		report(install.Event{Step: install.StepClone, Message: "Cloning repository (full)", Progress: &gitutil.Progress{Phase: "Receiving objects", Percent: pct, Current: pct, Total: 100, Bytes: 2048}})
+	}
+	report(install.Event{Step: install.StepClone, Message: "Cloned", Done: true})
+
+	want := "Cloning repository (full)\n" +
+		"  Receiving objects:   1% (1/100), 2.00 KiB\n" +
+		"  Receiving objects:  26% (26/100), 2.00 KiB\n" +
+		"  Receiving objects:  60% (60/100), 2.00 KiB\n" +
+		"  Receiving objects:  99% (99/100), 2.00 KiB\n" +
+		"  Receiving objects: 100% (100/100), 2.00 KiB\n"
+	if buf.String() != want {
+		t.Fatalf("output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -6,14 +6,59 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+
+	"github.com/kaofelix/skulls/internal/policy"
 )
 
 type configFile struct {
 	Dir string `json:"dir"`
+
+	// Policy restricts which sources can be installed from. It's combined with
+	// the optional system-wide policy file (see systemPolicyPath).
+	Policy *policy.Policy `json:"policy,omitempty"`
+
+	// RequireSigned makes every install behave as if --require-signed was
+	// passed. AllowedSigners (SSH allowed-signers file) and GPGHome (GnuPG
+	// home with the trusted keyring) select the keys used for verification.
+	RequireSigned  bool   `json:"require_signed,omitempty"`
+	AllowedSigners string `json:"allowed_signers,omitempty"`
+	GPGHome        string `json:"gpg_home,omitempty"`
+
+	// Registries are searched alongside skills.sh.
+	Registries []registryConfig `json:"registries,omitempty"`
+
+	// CacheTTL (e.g. "30m") serves cached HTTP responses younger than this
+	// without revalidating, regardless of the server's cache headers.
+	CacheTTL string `json:"cache_ttl,omitempty"`
+
+	// InstallTimeout (e.g. "5m") bounds each install, clone included. Empty
+	// means no limit; --timeout overrides it.
+	InstallTimeout string `json:"install_timeout,omitempty"`
+
+	// GitBackend selects how repositories are cloned: "git" (the git
+	// binary), "builtin" (in-process) or "auto" (the default: git when it's
+	// on PATH, builtin otherwise).
+	GitBackend string `json:"git_backend,omitempty"`
+
+	// SearchLimit and PopularLimit are how many results the search UI loads
+	// at a time for a query and for the popular list (default 10 and 50);
+	// scrolling to the end loads more. --limit overrides both.
+	SearchLimit  int `json:"search_limit,omitempty"`
+	PopularLimit int `json:"popular_limit,omitempty"`
+
+	// GitHubToken authenticates GitHub previews and clones when neither
+	// GITHUB_TOKEN nor GH_TOKEN is set.
+	GitHubToken string `json:"github_token,omitempty"`
+
+	// GitHubHosts are GitHub Enterprise instances, recognized in sources and
+	// used for previews alongside github.com.
+	GitHubHosts []githubHostConfig `json:"github_hosts,omitempty"`
 }
 
 var configPathFunc = defaultConfigPath
+var systemPolicyPathFunc = defaultSystemPolicyPath
 
 func runConfig(args []string) int {
 	if len(args) == 0 {
@@ -51,22 +96,46 @@ func runConfig(args []string) int {
 	}
 }
 
-func getInstallDir() (string, bool, error) {
+func loadConfig() (configFile, error) {
 	p, err := configPath()
 	if err != nil {
-		return "", false, err
+		return configFile{}, err
 	}
 
 	b, err := os.ReadFile(p)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return "", false, nil
+			return configFile{}, nil
 		}
-		return "", false, err
+		return configFile{}, err
 	}
 
 	var cfg configFile
 	if err := json.Unmarshal(b, &cfg); err != nil {
+		return configFile{}, err
+	}
+	return cfg, nil
+}
+
+func saveConfig(cfg configFile) error {
+	p, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	return os.WriteFile(p, b, 0o644)
+}
+
+func getInstallDir() (string, bool, error) {
+	cfg, err := loadConfig()
+	if err != nil {
 		return "", false, err
 	}
 	dir := strings.TrimSpace(cfg.Dir)
@@ -81,19 +150,36 @@ func setInstallDir(dir string) error {
 	if dir == "" {
 		return fmt.Errorf("dir must be non-empty")
 	}
-	p, err := configPath()
+	cfg, err := loadConfig()
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
-		return err
-	}
-	b, err := json.MarshalIndent(configFile{Dir: dir}, "", "  ")
+	cfg.Dir = dir
+	return saveConfig(cfg)
+}
+
+// loadPolicies returns the system-wide policy (if any) followed by the user's.
+func loadPolicies(cfg configFile) (policy.Set, error) {
+	var set policy.Set
+
+	p, err := systemPolicyPathFunc()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	b = append(b, '\n')
-	return os.WriteFile(p, b, 0o644)
+	if p != "" {
+		sys, err := policy.Load(p)
+		if err != nil {
+			return nil, err
+		}
+		if !sys.IsEmpty() {
+			set = append(set, sys)
+		}
+	}
+
+	if cfg.Policy != nil && !cfg.Policy.IsEmpty() {
+		set = append(set, *cfg.Policy)
+	}
+	return set, nil
 }
 
 func configPath() (string, error) {
@@ -107,3 +193,17 @@ func defaultConfigPath() (string, error) {
 	}
 	return filepath.Join(base, "skulls", "config.json"), nil
 }
+
+// defaultSystemPolicyPath returns the machine-wide policy file managed by
+// admins. There's deliberately no environment override: users must not be
+// able to swap out the policy that restricts them.
+func defaultSystemPolicyPath() (string, error) {
+	if runtime.GOOS == "windows" {
+		base := os.Getenv("ProgramData")
+		if base == "" {
+			return "", nil
+		}
+		return filepath.Join(base, "skulls", "policy.json"), nil
+	}
+	return "/etc/skulls/policy.json", nil
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kaofelix/skulls/internal/install"
+	"github.com/kaofelix/skulls/internal/textdiff"
+	"github.com/kaofelix/skulls/internal/tui"
+)
+
+const diffUsage = "Usage: skulls diff <skill-id> [--ref <ref>] [--dir <target-dir>] [--tui]\n"
+
+var runDiffFetch = install.FetchSkill
+var runDiffUI = tui.RunDiff
+
+type diffArgs struct {
+	SkillID   string
+	Ref       string
+	TargetDir string
+	TUI       bool
+	Help      bool
+}
+
+func parseDiffArgs(args []string) (diffArgs, error) {
+	var out diffArgs
+	var pos []string
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+
+		switch {
+		case a == "-h" || a == "--help":
+			out.Help = true
+		case a == "--tui":
+			out.TUI = true
+		case a == "-d" || a == "--dir":
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.TargetDir = args[i]
+		case strings.HasPrefix(a, "--dir="):
+			out.TargetDir = strings.TrimPrefix(a, "--dir=")
+		case a == "--ref":
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.Ref = args[i]
+		case strings.HasPrefix(a, "--ref="):
+			out.Ref = strings.TrimPrefix(a, "--ref=")
+		case strings.HasPrefix(a, "-"):
+			return out, fmt.Errorf("unknown flag: %s", a)
+		default:
+			pos = append(pos, a)
+		}
+	}
+
+	if out.Help {
+		return out, nil
+	}
+	if len(pos) != 1 {
+		return out, fmt.Errorf("expected exactly one skill-id")
+	}
+	out.SkillID = pos[0]
+	return out, nil
+}
+
+func runDiff(args []string) int {
+	parsed, err := parseDiffArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		fmt.Fprint(os.Stderr, diffUsage)
+		return 2
+	}
+	if parsed.Help {
+		fmt.Fprint(os.Stderr, diffUsage)
+		return 0
+	}
+	targetDir, _, err := resolveInstallDirForRun(parsed.TargetDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	folder := install.FolderName(parsed.SkillID)
+	md, err := install.ReadMetadata(targetDir, folder)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: no install metadata for %s (reinstall it with skulls add to enable diff): %v\n", folder, err)
+		return 1
+	}
+
+	opts, err := installOptionsForRun(targetDir, false, md.Source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	opts.Ref = md.Ref
+	opts.Path = md.Path
+	if v := strings.TrimSpace(parsed.Ref); v != "" {
+		opts.Ref = v
+	}
+	// Keep stdout for the diff itself.
+	opts.GitStdout = os.Stderr
+	opts.GitStderr = os.Stderr
+
+	ctx, cancel, err := installContextForRun("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	defer cancel()
+	upstreamDir, cleanup, err := runDiffFetch(ctx, md.ReinstallSource(), md.SkillID, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		printGitErrorHint(err, false)
+		return 1
+	}
+	defer cleanup()
+
+	installedDir, err := install.FolderPath(targetDir, folder)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	diffs, err := textdiff.Dirs(installedDir, upstreamDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	upstream := md.ReinstallSource()
+	if opts.Ref != "" {
+		upstream += "@" + opts.Ref
+	}
+	if len(diffs) == 0 {
+		fmt.Printf("No changes: %s matches %s\n", folder, upstream)
+		return 0
+	}
+
+	if parsed.TUI {
+		if err := runDiffUI(folder+" ↔ "+upstream, diffs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	for _, d := range diffs {
+		fmt.Print(d.Unified)
+	}
+	return 0
+}
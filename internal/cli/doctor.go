@@ -0,0 +1,413 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/install"
+)
+
+const doctorUsage = "Usage: skulls doctor [--dir <target-dir>]\n"
+
+// Minimum git version for the clone options skulls relies on.
+const (
+	minGitMajor = 2
+	minGitMinor = 25
+)
+
+// staleTempAge is how old a temp or staging dir must be before doctor
+// reports it, so installs that are still running aren't flagged.
+const staleTempAge = time.Hour
+
+var doctorTempDir = os.TempDir
+var doctorHTTPClient = &http.Client{Timeout: 5 * time.Second}
+var doctorEndpoints = defaultDoctorEndpoints
+
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+type doctorResult struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+	Fix    string
+}
+
+type doctorEndpoint struct {
+	Name string
+	URL  string
+}
+
+// defaultDoctorEndpoints returns skills.sh, GitHub and the API of each
+// configured GitHub Enterprise host. Extra registries get their own check.
+func defaultDoctorEndpoints(cfg configFile) []doctorEndpoint {
+	out := []doctorEndpoint{
+		{Name: "skills.sh", URL: "https://skills.sh"},
+		{Name: "GitHub API", URL: "https://api.github.com"},
+		{Name: "GitHub raw", URL: "https://raw.githubusercontent.com"},
+	}
+	for _, h := range cfg.GitHubHosts {
+		host := strings.ToLower(strings.TrimSpace(h.Host))
+		if host == "" {
+			continue
+		}
+		api := strings.TrimRight(strings.TrimSpace(h.APIBase), "/")
+		if api == "" {
+			api = "https://" + host + "/api/v3"
+		}
+		out = append(out, doctorEndpoint{Name: host + " API", URL: api})
+	}
+	return out
+}
+
+func runDoctor(args []string) int {
+	var dirFlag string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "-h" || a == "--help":
+			fmt.Fprint(os.Stderr, doctorUsage)
+			return 0
+		case a == "-d" || a == "--dir":
+			i++
+			if i >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n\n", a)
+				fmt.Fprint(os.Stderr, doctorUsage)
+				return 2
+			}
+			dirFlag = args[i]
+		case strings.HasPrefix(a, "--dir="):
+			dirFlag = strings.TrimPrefix(a, "--dir=")
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n\n", a)
+			fmt.Fprint(os.Stderr, doctorUsage)
+			return 2
+		}
+	}
+
+	var results []doctorResult
+	cfg, cfgResult := checkConfig()
+	results = append(results, checkGit(cfg), cfgResult)
+
+	targetDir, dirResult := checkTargetDir(dirFlag, cfg)
+	results = append(results, dirResult)
+	if targetDir != "" && dirResult.Status != doctorFail {
+		results = append(results, checkInstalledSkills(targetDir)...)
+	}
+
+	for _, ep := range doctorEndpoints(cfg) {
+		results = append(results, checkEndpoint(ep))
+	}
+	for _, rc := range cfg.Registries {
+		results = append(results, checkRegistry(rc))
+	}
+	staleDir := targetDir
+	if dirResult.Status == doctorFail {
+		staleDir = ""
+	}
+	results = append(results, checkStaleTempDirs(staleDir))
+
+	exit := 0
+	for _, r := range results {
+		mark := "✓"
+		switch r.Status {
+		case doctorWarn:
+			mark = "!"
+		case doctorFail:
+			mark = "✗"
+			exit = 1
+		}
+		fmt.Printf("%s %s: %s\n", mark, r.Name, r.Detail)
+		if r.Fix != "" && r.Status != doctorOK {
+			for _, line := range strings.Split(r.Fix, "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+	return exit
+}
+
+func checkGit(cfg configFile) doctorResult {
+	r := doctorResult{Name: "git"}
+	backend, err := gitutil.BackendFor(cfg.GitBackend)
+	if err != nil {
+		r.Status = doctorFail
+		r.Detail = err.Error()
+		r.Fix = `Set git_backend to "auto", "git" or "builtin" in the config.`
+		return r
+	}
+	v, err := gitutil.Version()
+	if err != nil {
+		if backend.Name() == gitutil.BackendBuiltin {
+			r.Status = doctorWarn
+			r.Detail = "not found; cloning with the built-in implementation"
+			r.Fix = "Installs work without git, but --require-signed needs it: https://git-scm.com/downloads"
+			return r
+		}
+		r.Status = doctorFail
+		r.Detail = err.Error()
+		r.Fix = "Install git and make sure it's on your PATH: https://git-scm.com/downloads"
+		return r
+	}
+	if backend.Name() == gitutil.BackendBuiltin {
+		r.Detail = "version " + v + " (cloning with the built-in implementation, per git_backend)"
+		return r
+	}
+	r.Detail = "version " + v
+	if !gitutil.VersionAtLeast(v, minGitMajor, minGitMinor) {
+		r.Status = doctorWarn
+		r.Detail += " is older than " + strconv.Itoa(minGitMajor) + "." + strconv.Itoa(minGitMinor)
+		r.Fix = "Upgrade git; some installs (pinned commits, signed refs) may fail on older versions."
+	}
+	return r
+}
+
+func checkConfig() (configFile, doctorResult) {
+	r := doctorResult{Name: "config"}
+	p, err := configPath()
+	if err != nil {
+		r.Status = doctorFail
+		r.Detail = err.Error()
+		r.Fix = "Set HOME (or XDG_CONFIG_HOME) so skulls can locate its config dir."
+		return configFile{}, r
+	}
+	if _, err := os.Stat(p); errors.Is(err, os.ErrNotExist) {
+		r.Detail = compactPath(p) + " (not created yet)"
+		return configFile{}, r
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		r.Status = doctorFail
+		r.Detail = compactPath(p) + ": " + err.Error()
+		r.Fix = "Fix the JSON in " + compactPath(p) + ", or delete it and run: skulls config set dir <path>"
+		return configFile{}, r
+	}
+	if _, err := loadPolicies(cfg); err != nil {
+		r.Status = doctorFail
+		r.Detail = compactPath(p) + ": policy: " + err.Error()
+		r.Fix = "Fix the system policy file (" + systemPolicyHint() + ") or the \"policy\" key in the config."
+		return cfg, r
+	}
+	r.Detail = compactPath(p)
+	return cfg, r
+}
+
+func checkTargetDir(flagValue string, cfg configFile) (string, doctorResult) {
+	r := doctorResult{Name: "target dir"}
+	dir := strings.TrimSpace(flagValue)
+	if dir == "" {
+		dir = strings.TrimSpace(cfg.Dir)
+	}
+	if dir == "" {
+		r.Status = doctorWarn
+		r.Detail = "not configured"
+		r.Fix = "Set a default install dir: skulls config set dir <path>"
+		return "", r
+	}
+
+	abs, err := install.FolderPath(dir, "")
+	if err != nil {
+		r.Status = doctorFail
+		r.Detail = err.Error()
+		return "", r
+	}
+	info, err := os.Stat(abs)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		r.Status = doctorWarn
+		r.Detail = compactPath(abs) + " does not exist yet"
+		r.Fix = "It will be created on the first install, or create it now: mkdir -p " + strconv.Quote(abs)
+		return dir, r
+	case err != nil:
+		r.Status = doctorFail
+		r.Detail = err.Error()
+		return "", r
+	case !info.IsDir():
+		r.Status = doctorFail
+		r.Detail = compactPath(abs) + " is not a directory"
+		r.Fix = "Point skulls at a directory: skulls config set dir <path>"
+		return "", r
+	}
+
+	f, err := os.CreateTemp(abs, ".skulls-doctor-*")
+	if err != nil {
+		r.Status = doctorFail
+		r.Detail = compactPath(abs) + " is not writable: " + err.Error()
+		r.Fix = "Fix its permissions (e.g. chmod u+w " + strconv.Quote(abs) + ") or choose another dir with --dir."
+		return "", r
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	r.Detail = compactPath(abs)
+	return dir, r
+}
+
+func checkInstalledSkills(targetDir string) []doctorResult {
+	skills, err := install.ListInstalled(targetDir)
+	if err != nil {
+		return []doctorResult{{Name: "skills", Status: doctorFail, Detail: err.Error()}}
+	}
+
+	var out []doctorResult
+	for _, s := range skills {
+		if s.Valid {
+			continue
+		}
+		r := doctorResult{
+			Name:   "skill " + s.Folder,
+			Status: doctorWarn,
+			Detail: "SKILL.md is missing or has invalid frontmatter",
+			Fix:    "Remove " + strconv.Quote(s.Path) + " or reinstall it with skulls add <source> <skill-id> --force",
+		}
+		if s.Metadata != nil {
+			r.Fix = fmt.Sprintf("Reinstall it: skulls add %s %s --force", s.Metadata.ReinstallSource(), s.Metadata.SkillID)
+		}
+		out = append(out, r)
+	}
+
+	orphans, err := install.OrphanedMetadata(targetDir)
+	if err != nil {
+		out = append(out, doctorResult{Name: "metadata", Status: doctorFail, Detail: err.Error()})
+	}
+	for _, folder := range orphans {
+		p, _ := install.FolderPath(targetDir, filepath.Join(install.MetadataDirName, folder+".json"))
+		out = append(out, doctorResult{
+			Name:   "metadata " + folder,
+			Status: doctorWarn,
+			Detail: "install metadata without a skill folder",
+			Fix:    "Remove the stale file: rm " + strconv.Quote(p),
+		})
+	}
+
+	if len(out) == 0 {
+		valid := 0
+		for _, s := range skills {
+			if s.Valid {
+				valid++
+			}
+		}
+		out = append(out, doctorResult{Name: "skills", Detail: fmt.Sprintf("%d installed", valid)})
+	}
+	return out
+}
+
+func checkEndpoint(ep doctorEndpoint) doctorResult {
+	r := doctorResult{Name: ep.Name}
+	ctx, cancel := context.WithTimeout(context.Background(), doctorHTTPClient.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, ep.URL, nil)
+	if err != nil {
+		r.Status = doctorFail
+		r.Detail = err.Error()
+		return r
+	}
+	resp, err := doctorHTTPClient.Do(req)
+	if err != nil {
+		r.Status = doctorWarn
+		r.Detail = "unreachable: " + err.Error()
+		r.Fix = "Check your network, proxy (HTTPS_PROXY) or firewall settings."
+		return r
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		r.Status = doctorWarn
+		r.Detail = ep.URL + " returned " + resp.Status
+		r.Fix = "The service may be down; try again later."
+		return r
+	}
+	r.Detail = ep.URL + " reachable"
+	return r
+}
+
+// checkRegistry asks a configured registry for one popular skill, which
+// exercises both reachability and the response format.
+func checkRegistry(rc registryConfig) doctorResult {
+	r := doctorResult{Name: "registry " + rc.Name}
+	reg, err := newRegistry(rc, doctorHTTPClient, githubAuth{})
+	if err != nil {
+		r.Status = doctorFail
+		r.Detail = err.Error()
+		r.Fix = "Fix the \"registries\" entry in the config."
+		return r
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), doctorHTTPClient.Timeout)
+	defer cancel()
+	if _, err := reg.Popular(ctx, 1); err != nil {
+		r.Status = doctorWarn
+		r.Detail = err.Error()
+		r.Fix = "Check the registry url (" + rc.URL + ") and your network; search will skip it until it responds."
+		return r
+	}
+	r.Detail = rc.URL + " reachable"
+	return r
+}
+
+// checkStaleTempDirs reports skulls-* clones in the temp dir and, when
+// targetDir is set, the .skulls-staging-* and .previous dirs an interrupted
+// install leaves next to the skills.
+func checkStaleTempDirs(targetDir string) doctorResult {
+	r := doctorResult{Name: "temp dirs"}
+	tmp := doctorTempDir()
+	patterns := []string{filepath.Join(tmp, "skulls-*")}
+	dirs := []string{compactPath(tmp)}
+	if targetDir != "" {
+		patterns = append(patterns, filepath.Join(targetDir, ".skulls-staging-*"), filepath.Join(targetDir, ".previous"))
+		dirs = append(dirs, compactPath(targetDir))
+	}
+
+	var stale []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			r.Status = doctorWarn
+			r.Detail = err.Error()
+			return r
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			if time.Since(info.ModTime()) >= staleTempAge {
+				stale = append(stale, m)
+			}
+		}
+	}
+	if len(stale) == 0 {
+		r.Detail = "no stale install dirs"
+		return r
+	}
+
+	r.Status = doctorWarn
+	r.Detail = fmt.Sprintf("%d stale dir(s) in %s (left by interrupted installs)", len(stale), strings.Join(dirs, " and "))
+	quoted := make([]string, 0, len(stale))
+	for _, s := range stale {
+		quoted = append(quoted, strconv.Quote(s))
+	}
+	r.Fix = "Remove them: rm -rf " + strings.Join(quoted, " ")
+	return r
+}
+
+// systemPolicyHint names the system policy file for doctor's advice.
+func systemPolicyHint() string {
+	p, err := systemPolicyPathFunc()
+	if err != nil || p == "" {
+		return "no system policy location on this machine"
+	}
+	return compactPath(p)
+}
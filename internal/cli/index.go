@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/install"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+const indexUsage = "Usage: skulls index build <source[#ref]>... [-o <file>]\n"
+
+var indexNow = time.Now
+
+type indexBuildArgs struct {
+	Output  string
+	Help    bool
+	Sources []string
+}
+
+func runIndex(args []string) int {
+	if len(args) == 0 || args[0] != "build" {
+		if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+			fmt.Fprint(os.Stderr, indexUsage)
+			return 0
+		}
+		fmt.Fprint(os.Stderr, indexUsage)
+		return 2
+	}
+	return runIndexBuild(args[1:])
+}
+
+func parseIndexBuildArgs(args []string) (indexBuildArgs, error) {
+	var out indexBuildArgs
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+
+		switch {
+		case a == "-h" || a == "--help":
+			out.Help = true
+		case a == "-o" || a == "--output":
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.Output = args[i]
+		case strings.HasPrefix(a, "--output="):
+			out.Output = strings.TrimPrefix(a, "--output=")
+		case strings.HasPrefix(a, "-"):
+			return out, fmt.Errorf("unknown flag: %s", a)
+		default:
+			out.Sources = append(out.Sources, a)
+		}
+	}
+
+	if !out.Help && len(out.Sources) == 0 {
+		return out, fmt.Errorf("at least one source is required")
+	}
+	return out, nil
+}
+
+func runIndexBuild(args []string) int {
+	parsed, err := parseIndexBuildArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		fmt.Fprint(os.Stderr, indexUsage)
+		return 2
+	}
+	if parsed.Help {
+		fmt.Fprint(os.Stderr, indexUsage)
+		return 0
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	pol, err := loadPolicies(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	git, err := gitutil.BackendFor(cfg.GitBackend)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	index := skillsapi.IndexFile{Version: skillsapi.IndexVersion, GeneratedAt: indexNow().UTC()}
+	for _, arg := range parsed.Sources {
+		source, ref := splitSourceRef(arg)
+		discovered, cleanup, err := install.DiscoverSkills(ctx, source, install.DiscoverOptions{Ref: ref, Policy: pol, Git: git})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", arg, err)
+			printGitErrorHint(err, true)
+			return 1
+		}
+		source = indexSource(source)
+		for _, d := range discovered {
+			index.Skills = append(index.Skills, skillsapi.Skill{
+				ID:          source + "/" + d.Name,
+				SkillID:     d.Name,
+				Name:        d.Name,
+				Description: d.Description,
+				Source:      source,
+				Ref:         ref,
+				Path:        d.Path,
+				Tags:        d.Tags,
+			})
+		}
+		cleanup()
+		fmt.Fprintf(os.Stderr, "Indexed %d skill(s) from %s\n", len(discovered), arg)
+	}
+
+	b, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	b = append(b, '\n')
+
+	if parsed.Output == "" || parsed.Output == "-" {
+		_, _ = os.Stdout.Write(b)
+		return 0
+	}
+	if err := os.WriteFile(parsed.Output, b, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d skill(s) to %s\n", len(index.Skills), parsed.Output)
+	return 0
+}
+
+// splitSourceRef splits "source#ref" into its parts.
+func splitSourceRef(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "#"); i > 0 {
+		return s[:i], strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+// indexSource returns the source to record for s: local paths are made
+// absolute so the index works from any directory, everything else is kept
+// as written.
+func indexSource(s string) string {
+	u, err := gitutil.NormalizeSourceToGitURL(s)
+	if err != nil || !filepath.IsAbs(u) {
+		return s
+	}
+	return u
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/install"
+)
+
+// plainCloneProgress reports clone progress without a TTY: the clone
+// message, then one compact line per phase each time it passes another
+// quarter, so logs stay short.
+func plainCloneProgress(w io.Writer) install.ProgressFunc {
+	reported := map[string]int{}
+	return func(e install.Event) {
+		if e.Step != install.StepClone || e.Done {
+			return
+		}
+		if e.Progress == nil {
+			fmt.Fprintln(w, e.Message)
+			return
+		}
+		p := *e.Progress
+		quarter := p.Percent / 25
+		if last, seen := reported[p.Phase]; seen && quarter <= last {
+			return
+		}
+		reported[p.Phase] = quarter
+		fmt.Fprintf(w, "  %s\n", plainProgressLine(p))
+	}
+}
+
+func plainProgressLine(p gitutil.Progress) string {
+	line := fmt.Sprintf("%s: %3d%% (%d/%d)", p.Phase, p.Percent, p.Current, p.Total)
+	if p.Bytes > 0 {
+		line += ", " + gitutil.FormatBytes(p.Bytes)
+	}
+	return line
+}
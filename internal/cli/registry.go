@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kaofelix/skulls/internal/httpcache"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+// registryConfig is an extra skills registry searched alongside skills.sh.
+type registryConfig struct {
+	Name string `json:"name"`
+	// Type is "http" for a catalog serving the skills.sh search API, or
+	// "index" for a static JSON index (local path or URL).
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// newRegistry builds the registry described by rc. httpClient may be nil.
+func newRegistry(rc registryConfig, httpClient *http.Client, gh githubAuth) (skillsapi.Registry, error) {
+	u := strings.TrimSpace(rc.URL)
+	if u == "" {
+		return nil, fmt.Errorf("registry %q: url is required", rc.Name)
+	}
+	switch strings.ToLower(strings.TrimSpace(rc.Type)) {
+	case "http", "":
+		return skillsapi.Catalog{Label: strings.TrimSpace(rc.Name), BaseURL: u, HTTP: httpClient, GitHubToken: gh.Token, GitHubHosts: gh.Hosts}, nil
+	case "index":
+		return &skillsapi.Index{Label: strings.TrimSpace(rc.Name), Location: expandUserPath(u), HTTP: httpClient, GitHubToken: gh.Token, GitHubHosts: gh.Hosts}, nil
+	default:
+		return nil, fmt.Errorf("registry %q: unknown type %q (want \"http\" or \"index\")", rc.Name, rc.Type)
+	}
+}
+
+// registryForRun returns skills.sh, merged with any registries from the
+// config, using gh for previews. httpClient may be nil.
+func registryForRun(cfg configFile, httpClient *http.Client, gh githubAuth) (skillsapi.Registry, error) {
+	skillsSh := skillsapi.Client{HTTP: httpClient, GitHubToken: gh.Token, GitHubHosts: gh.Hosts}
+	if len(cfg.Registries) == 0 {
+		return skillsSh, nil
+	}
+	multi := skillsapi.Multi{skillsSh}
+	for _, rc := range cfg.Registries {
+		r, err := newRegistry(rc, httpClient, gh)
+		if err != nil {
+			return nil, err
+		}
+		multi = append(multi, r)
+	}
+	return multi, nil
+}
+
+// httpTimeout matches the per-request timeout skillsapi uses by default.
+const httpTimeout = 10 * time.Second
+
+// httpClientForRun returns the HTTP client for registry and preview requests:
+// cached on disk unless noCache is set, with cfg.CacheTTL overriding server
+// freshness.
+func httpClientForRun(cfg configFile, noCache bool) (*http.Client, error) {
+	if noCache {
+		return &http.Client{Timeout: httpTimeout}, nil
+	}
+	var ttl time.Duration
+	if v := strings.TrimSpace(cfg.CacheTTL); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid cache_ttl %q (use a duration like \"10m\" or \"1h\")", v)
+		}
+		ttl = d
+	}
+	dir, err := cacheDirFunc()
+	if err != nil {
+		return nil, err
+	}
+	return httpcache.Client(filepath.Join(dir, "http"), ttl, httpTimeout), nil
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/install"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+// githubHostConfig is a GitHub Enterprise instance from the config. APIBase
+// and RawBase default to https://<host>/api/v3 and https://<host>/raw.
+type githubHostConfig struct {
+	Host    string `json:"host"`
+	APIBase string `json:"api_base,omitempty"`
+	RawBase string `json:"raw_base,omitempty"`
+	Token   string `json:"token,omitempty"`
+}
+
+// githubAuth holds the GitHub token and the enterprise hosts with their
+// tokens, for previews and clones.
+type githubAuth struct {
+	Token string
+	Hosts []skillsapi.GitHubHost
+}
+
+// ghAuthToken asks the GitHub CLI for its token for host; stubbed in tests.
+var ghAuthToken = func(host string) string {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return ""
+	}
+	out, err := exec.Command("gh", "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// githubTokenForRun returns the github.com token to use, from GITHUB_TOKEN,
+// GH_TOKEN, the config or `gh auth token`, in that order. Empty means
+// unauthenticated.
+func githubTokenForRun(cfg configFile) string {
+	for _, v := range []string{os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN"), cfg.GitHubToken} {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ghAuthToken("github.com")
+}
+
+// githubAuthForRun resolves tokens for github.com and each configured
+// enterprise host. Enterprise tokens come from the host's config entry or
+// `gh auth token --hostname`.
+func githubAuthForRun(cfg configFile) githubAuth {
+	return githubAuthFor(cfg, func(string) bool { return true })
+}
+
+// githubAuthForSources is githubAuthForRun for installing from sources: only
+// the hosts they clone from get a token, so local paths, archives and other
+// forges never run `gh auth token`.
+func githubAuthForSources(cfg configFile, sources ...string) githubAuth {
+	hosts := map[string]bool{}
+	for _, s := range sources {
+		if install.IsArchiveSource(s) {
+			continue
+		}
+		if u, err := gitutil.NormalizeSourceToGitURL(s); err == nil {
+			if host := gitutil.ParseRemote(u).Host; host != "" {
+				hosts[host] = true
+			}
+		}
+	}
+	return githubAuthFor(cfg, func(host string) bool { return hosts[host] })
+}
+
+// githubAuthFor lists the configured enterprise hosts, resolving tokens for
+// the ones (and github.com) that need reports true.
+func githubAuthFor(cfg configFile, need func(host string) bool) githubAuth {
+	var out githubAuth
+	if need("github.com") {
+		out.Token = githubTokenForRun(cfg)
+	}
+	for _, h := range cfg.GitHubHosts {
+		host := strings.ToLower(strings.TrimSpace(h.Host))
+		if host == "" {
+			continue
+		}
+		var token string
+		if need(host) {
+			if token = strings.TrimSpace(h.Token); token == "" {
+				token = ghAuthToken(host)
+			}
+		}
+		out.Hosts = append(out.Hosts, skillsapi.GitHubHost{
+			Host:    host,
+			APIBase: strings.TrimSpace(h.APIBase),
+			RawBase: strings.TrimSpace(h.RawBase),
+			Token:   token,
+		})
+	}
+	return out
+}
+
+// gitTokens returns the git credentials for HTTPS clones.
+func (a githubAuth) gitTokens() []gitutil.Token {
+	var out []gitutil.Token
+	if a.Token != "" {
+		out = append(out, gitutil.Token{Host: "github.com", Value: a.Token})
+	}
+	for _, h := range a.Hosts {
+		if h.Token != "" {
+			out = append(out, gitutil.Token{Host: h.Host, Value: h.Token})
+		}
+	}
+	return out
+}
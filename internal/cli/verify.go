@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kaofelix/skulls/internal/install"
+)
+
+const verifyUsage = "Usage: skulls verify [skill-id...] [--dir <target-dir>] [--restore]\n"
+
+var runVerifyRestore = func(ctx context.Context, md install.Metadata, opts installOptions) (string, error) {
+	opts.Force = true
+	opts.Ref = md.ReinstallRef()
+	opts.SHA256 = md.SHA256
+	opts.Path = md.Path
+	return install.InstallSkill(ctx, md.ReinstallSource(), md.SkillID, opts)
+}
+
+type verifyArgs struct {
+	TargetDir string
+	Restore   bool
+	Help      bool
+	SkillIDs  []string
+}
+
+func parseVerifyArgs(args []string) (verifyArgs, error) {
+	var out verifyArgs
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+
+		switch {
+		case a == "-h" || a == "--help":
+			out.Help = true
+		case a == "--restore":
+			out.Restore = true
+		case a == "-d" || a == "--dir":
+			i++
+			if i >= len(args) {
+				return out, fmt.Errorf("%s requires a value", a)
+			}
+			out.TargetDir = args[i]
+		case strings.HasPrefix(a, "--dir="):
+			out.TargetDir = strings.TrimPrefix(a, "--dir=")
+		case strings.HasPrefix(a, "-"):
+			return out, fmt.Errorf("unknown flag: %s", a)
+		default:
+			out.SkillIDs = append(out.SkillIDs, a)
+		}
+	}
+
+	return out, nil
+}
+
+func runVerify(args []string) int {
+	parsed, err := parseVerifyArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		fmt.Fprint(os.Stderr, verifyUsage)
+		return 2
+	}
+	if parsed.Help {
+		fmt.Fprint(os.Stderr, verifyUsage)
+		return 0
+	}
+	targetDir, _, err := resolveInstallDirForRun(parsed.TargetDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	folders := make([]string, 0, len(parsed.SkillIDs))
+	for _, id := range parsed.SkillIDs {
+		folders = append(folders, install.FolderName(id))
+	}
+	if len(folders) == 0 {
+		installed, err := install.ListInstalled(targetDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		for _, s := range installed {
+			if s.Metadata != nil {
+				folders = append(folders, s.Folder)
+			}
+		}
+		if len(folders) == 0 {
+			fmt.Printf("No skills with install metadata in %s\n", compactPath(targetDir))
+			return 0
+		}
+	}
+
+	var cfg configFile
+	var opts installOptions
+	if parsed.Restore {
+		if cfg, err = loadConfig(); err == nil {
+			// Tokens are resolved per restored source.
+			opts, err = installOptionsFor(cfg, githubAuth{}, targetDir, true)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+	}
+
+	exit := 0
+	for _, folder := range folders {
+		report, err := install.VerifyInstalled(targetDir, folder)
+		if err != nil {
+			if errors.Is(err, install.ErrNoDigest) {
+				fmt.Printf("? %s: %v\n", folder, err)
+				continue
+			}
+			fmt.Printf("✗ %s: %v\n", folder, err)
+			exit = 1
+			continue
+		}
+		if report.Clean() {
+			fmt.Printf("✓ %s\n", folder)
+			continue
+		}
+
+		fmt.Printf("✗ %s: modified since install\n", folder)
+		printFileChanges("M", report.Modified)
+		printFileChanges("A", report.Added)
+		printFileChanges("D", report.Deleted)
+
+		if !parsed.Restore {
+			exit = 1
+			continue
+		}
+		opts.Tokens = githubAuthForSources(cfg, report.Metadata.ReinstallSource()).gitTokens()
+		ctx, cancel, err := installContextForRun("")
+		if err == nil {
+			_, err = runVerifyRestore(ctx, report.Metadata, opts)
+			cancel()
+		}
+		if err != nil {
+			fmt.Printf("  restore failed: %v\n", err)
+			exit = 1
+			continue
+		}
+		fmt.Printf("  restored from %s\n", report.Metadata.ReinstallSource())
+	}
+
+	if exit != 0 && !parsed.Restore {
+		fmt.Fprint(os.Stderr, "\nRun with --restore to reinstall the recorded version over modified skills.\n")
+	}
+	return exit
+}
+
+func printFileChanges(kind string, paths []string) {
+	for _, p := range paths {
+		fmt.Printf("    %s %s\n", kind, p)
+	}
+}
@@ -9,6 +9,13 @@ import (
 )
 
 func CopyDir(src string, dst string) error {
+	return CopyDirSkipping(src, dst, nil)
+}
+
+// CopyDirSkipping is CopyDir leaving out the paths skip returns true for.
+// skip gets slash-separated paths relative to src; skipping a directory
+// skips everything in it.
+func CopyDirSkipping(src string, dst string, skip func(rel string, isDir bool) bool) error {
 	src = filepath.Clean(src)
 	dst = filepath.Clean(dst)
 
@@ -35,6 +42,12 @@ func CopyDir(src string, dst string) error {
 		if rel == "." {
 			return nil
 		}
+		if skip != nil && skip(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		outPath := filepath.Join(dst, rel)
 
 		if d.IsDir() {
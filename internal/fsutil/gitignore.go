@@ -0,0 +1,181 @@
+package fsutil
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Ignore matches paths against the .gitignore files of a directory tree.
+// It covers the common syntax: comments, negation, directory-only patterns,
+// anchoring, and *, ?, [...] and ** wildcards.
+type Ignore struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// LoadGitignore reads every .gitignore under root. Patterns in a nested
+// .gitignore apply relative to its directory. Ignored directories aren't
+// walked, as git doesn't look inside them either. A tree without any
+// .gitignore yields an Ignore that matches nothing.
+func LoadGitignore(root string) (*Ignore, error) {
+	ig := &Ignore{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		base := filepath.ToSlash(rel)
+		if base == "." {
+			base = ""
+		}
+		if base != "" && ig.Match(base, true) {
+			return filepath.SkipDir
+		}
+		// Read the directory's own rules before walking its entries, which
+		// may sort ahead of .gitignore.
+		file := filepath.Join(p, ".gitignore")
+		if fi, err := os.Stat(file); err != nil || !fi.Mode().IsRegular() {
+			return nil
+		}
+		return ig.addFile(file, base)
+	})
+	return ig, err
+}
+
+func (ig *Ignore) addFile(file string, base string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if r, ok := parseIgnoreLine(sc.Text(), base); ok {
+			ig.rules = append(ig.rules, r)
+		}
+	}
+	return sc.Err()
+}
+
+func parseIgnoreLine(line string, base string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	var r ignoreRule
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, "\\")
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to its .gitignore's
+	// directory; otherwise it matches at any depth.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if base != "" {
+		b.WriteString(regexp.QuoteMeta(base) + "/")
+	}
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	b.WriteString(globToRegexp(line))
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// Match reports whether the slash-separated path rel is ignored. The last
+// matching pattern wins, as in git. Callers walking a tree should skip
+// ignored directories, since git doesn't look inside them either.
+func (ig *Ignore) Match(rel string, isDir bool) bool {
+	if ig == nil {
+		return false
+	}
+	rel = path.Clean(filepath.ToSlash(rel))
+	ignored := false
+	for _, r := range ig.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.re.MatchString(rel) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,80 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnore_Match(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel string, body string) {
+		t.Helper()
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "# build output\n*.log\n!keep.log\nnode_modules/\n/dist\ndocs/**/*.tmp\n")
+	write("skills/lint/.gitignore", "cache\n")
+
+	ig, err := LoadGitignore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"skills/lint/debug.log", false, true},
+		{"keep.log", false, false},
+		{"node_modules", true, true},
+		{"node_modules", false, false},
+		{"skills/node_modules", true, true},
+		{"dist", true, true},
+		{"skills/dist", true, false},
+		{"docs/a/b/x.tmp", false, true},
+		{"docs/x.tmp", false, true},
+		{"skills/lint/cache", true, true},
+		{"cache", true, false},
+		{"skills/lint/SKILL.md", false, false},
+	}
+	for _, tt := range tests {
+		if got := ig.Match(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, dir=%v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestLoadGitignore_SkipsIgnoredDirectories(t *testing.T) {
+	root := t.TempDir()
+	for rel, body := range map[string]string{
+		".gitignore":                    "node_modules/\n",
+		"node_modules/pkg/.gitignore":   "*.md\n",
+		"skills/lint/.gitignore":        ".cache\n",
+		"skills/lint/.cache/.gitignore": "*\n",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ig, err := LoadGitignore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the root and skills/lint: .cache sorts ahead of skills/lint's own
+	// .gitignore but is still skipped.
+	if len(ig.rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(ig.rules))
+	}
+}
@@ -0,0 +1,70 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Backend clones repositories. Exec runs the git binary; Builtin clones
+// in-process, for machines without git.
+type Backend interface {
+	// Name is the git_backend config value selecting this backend.
+	Name() string
+	// Clone makes a shallow clone of url at opts.Ref into dest, as Clone.
+	Clone(ctx context.Context, url string, dest string, opts CloneOptions) error
+	// HeadCommit returns the commit SHA checked out in repoDir.
+	HeadCommit(repoDir string) (string, error)
+	// RemoteCommit returns the commit ref points to on url, as RemoteCommit.
+	RemoteCommit(ctx context.Context, url string, ref string, opts RemoteOptions) (string, error)
+}
+
+// Backend names accepted by BackendFor.
+const (
+	BackendAuto    = "auto"
+	BackendExec    = "git"
+	BackendBuiltin = "builtin"
+)
+
+var (
+	Exec    Backend = execBackend{}
+	Builtin Backend = builtinBackend{}
+)
+
+// BackendFor returns the backend named by a git_backend config value. Auto
+// (or empty) uses the git binary when it's on PATH and Builtin otherwise.
+func BackendFor(name string) (Backend, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", BackendAuto:
+		if _, err := exec.LookPath("git"); err != nil {
+			return Builtin, nil
+		}
+		return Exec, nil
+	case BackendExec:
+		return Exec, nil
+	case BackendBuiltin:
+		return Builtin, nil
+	}
+	return nil, fmt.Errorf("unknown git backend %q (use %q, %q or %q)", name, BackendAuto, BackendExec, BackendBuiltin)
+}
+
+// DefaultBackend is BackendFor(BackendAuto).
+func DefaultBackend() Backend {
+	b, _ := BackendFor(BackendAuto)
+	return b
+}
+
+type execBackend struct{}
+
+func (execBackend) Name() string { return BackendExec }
+
+func (execBackend) Clone(ctx context.Context, url string, dest string, opts CloneOptions) error {
+	return Clone(ctx, url, dest, opts)
+}
+
+func (execBackend) HeadCommit(repoDir string) (string, error) { return HeadCommit(repoDir) }
+
+func (execBackend) RemoteCommit(ctx context.Context, url string, ref string, opts RemoteOptions) (string, error) {
+	return RemoteCommit(ctx, url, ref, opts)
+}
@@ -0,0 +1,262 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// builtinBackend clones with go-git. It supports HTTPS (with Tokens), SSH
+// (through the SSH agent and known_hosts, like git) and local repositories.
+// SparsePaths is ignored: it always makes a full shallow checkout.
+type builtinBackend struct{}
+
+func (builtinBackend) Name() string { return BackendBuiltin }
+
+var installFileTransport sync.Once
+
+// localLoader serves local repositories, bare or not, to the in-process
+// file transport.
+type localLoader struct{}
+
+func (localLoader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	r, err := git.PlainOpen(ep.Path)
+	if err != nil {
+		return nil, transport.ErrRepositoryNotFound
+	}
+	return r.Storer, nil
+}
+
+// useInProcessFileTransport replaces go-git's own file transport, which runs
+// git-upload-pack, with one serving local repositories in-process so no git
+// binary is needed.
+func useInProcessFileTransport() {
+	installFileTransport.Do(func() {
+		client.InstallProtocol("file", server.NewClient(localLoader{}))
+	})
+}
+
+func (builtinBackend) Clone(ctx context.Context, url string, dest string, opts CloneOptions) error {
+	useInProcessFileTransport()
+
+	ep, err := transport.NewEndpoint(url)
+	if err != nil {
+		return err
+	}
+	// The in-process server can't produce shallow packs; local clones are
+	// cheap anyway.
+	depth := 1
+	if ep.Protocol == "file" {
+		depth = 0
+	}
+	progress, _ := opts.progressStderr()
+	auth := builtinAuth(ep, opts.Tokens)
+
+	ref := strings.TrimSpace(opts.Ref)
+	if commitSHARe.MatchString(ref) {
+		err := builtinFetchCommit(ctx, url, dest, ref, depth, auth, progress)
+		if errors.Is(err, git.ErrExactSHA1NotSupported) {
+			// Servers that can't send a single commit (including the
+			// in-process one) get a full clone instead.
+			if err = os.RemoveAll(dest); err == nil {
+				err = builtinCloneCommit(ctx, url, dest, ref, auth, progress)
+			}
+		}
+		return builtinError(ctx, "fetch", err)
+	}
+
+	co := &git.CloneOptions{URL: url, Auth: auth, Depth: depth, SingleBranch: true, Tags: git.NoTags, Progress: progress}
+	if ref == "" {
+		_, err = git.PlainCloneContext(ctx, dest, false, co)
+		return builtinError(ctx, "clone", err)
+	}
+	// Like `git clone --branch`, ref may name a branch or a tag.
+	co.ReferenceName = plumbing.NewBranchReferenceName(ref)
+	_, err = git.PlainCloneContext(ctx, dest, false, co)
+	if isUnknownRef(err) {
+		co.ReferenceName = plumbing.NewTagReferenceName(ref)
+		_, err = git.PlainCloneContext(ctx, dest, false, co)
+	}
+	return builtinError(ctx, "clone", err)
+}
+
+// builtinFetchCommit fetches just commit sha into a new repository at dest.
+func builtinFetchCommit(ctx context.Context, url string, dest string, sha string, depth int, auth transport.AuthMethod, progress io.Writer) error {
+	r, err := git.PlainInit(dest, false)
+	if err != nil {
+		return err
+	}
+	remote, err := r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url}})
+	if err != nil {
+		return err
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(sha + ":refs/heads/skulls")},
+		Depth:    depth,
+		Auth:     auth,
+		Tags:     git.NoTags,
+		Progress: progress,
+	})
+	if err != nil {
+		return err
+	}
+	return checkoutHash(r, sha)
+}
+
+// builtinCloneCommit clones all of url and checks out commit sha.
+func builtinCloneCommit(ctx context.Context, url string, dest string, sha string, auth transport.AuthMethod, progress io.Writer) error {
+	r, err := git.PlainCloneContext(ctx, dest, false, &git.CloneOptions{URL: url, Auth: auth, NoCheckout: true, Progress: progress})
+	if err != nil {
+		return err
+	}
+	return checkoutHash(r, sha)
+}
+
+func checkoutHash(r *git.Repository, sha string) error {
+	wt, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(sha)}); err != nil {
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return plumbing.ErrReferenceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (builtinBackend) HeadCommit(repoDir string) (string, error) {
+	r, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return "", err
+	}
+	head, err := r.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// RemoteCommit never prompts: go-git has no terminal prompts, so
+// opts.NonInteractive needs nothing extra here.
+func (builtinBackend) RemoteCommit(ctx context.Context, url string, ref string, opts RemoteOptions) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if commitSHARe.MatchString(ref) {
+		return ref, nil
+	}
+	useInProcessFileTransport()
+	ep, err := transport.NewEndpoint(url)
+	if err != nil {
+		return "", err
+	}
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{url}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: builtinAuth(ep, opts.Tokens), PeelingOption: git.AppendPeeled})
+	if err != nil {
+		return "", builtinError(ctx, "ls-remote", err)
+	}
+	byName := map[plumbing.ReferenceName]*plumbing.Reference{}
+	for _, r := range refs {
+		byName[r.Name()] = r
+	}
+	// Same preference as RemoteCommit: branch, peeled tag, tag.
+	names := []plumbing.ReferenceName{plumbing.HEAD}
+	if ref != "" {
+		tag := plumbing.NewTagReferenceName(ref)
+		names = []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), tag + "^{}", tag}
+	}
+	for _, name := range names {
+		r, ok := byName[name]
+		if ok && r.Type() == plumbing.SymbolicReference {
+			r, ok = byName[r.Target()]
+		}
+		if ok {
+			return peelLocalTag(ep, r.Hash()).String(), nil
+		}
+	}
+	return "", &GitError{Command: "ls-remote", Kind: ErrUnknownRef, Output: "couldn't find remote ref " + ref, Err: plumbing.ErrReferenceNotFound}
+}
+
+// peelLocalTag returns the commit of annotated tag h for local repositories,
+// which the in-process server lists without peeled refs. Anything else is
+// returned as is.
+func peelLocalTag(ep *transport.Endpoint, h plumbing.Hash) plumbing.Hash {
+	if ep.Protocol != "file" {
+		return h
+	}
+	r, err := git.PlainOpen(ep.Path)
+	if err != nil {
+		return h
+	}
+	tag, err := r.TagObject(h)
+	if err != nil {
+		return h
+	}
+	c, err := tag.Commit()
+	if err != nil {
+		return h
+	}
+	return c.Hash
+}
+
+// builtinAuth returns the HTTPS token for ep's host, if any. SSH endpoints
+// get nil, which makes go-git use the SSH agent.
+func builtinAuth(ep *transport.Endpoint, tokens []Token) transport.AuthMethod {
+	if ep.Protocol != "https" {
+		return nil
+	}
+	host := strings.ToLower(ep.Host)
+	if ep.Port != 0 && ep.Port != 443 {
+		host = fmt.Sprintf("%s:%d", host, ep.Port)
+	}
+	for _, t := range tokens {
+		if strings.ToLower(strings.TrimSpace(t.Host)) == host && strings.TrimSpace(t.Value) != "" {
+			return &http.BasicAuth{Username: "x-access-token", Password: strings.TrimSpace(t.Value)}
+		}
+	}
+	return nil
+}
+
+func isUnknownRef(err error) bool {
+	var noMatch git.NoMatchingRefSpecError
+	return errors.Is(err, plumbing.ErrReferenceNotFound) || errors.As(err, &noMatch)
+}
+
+// builtinError converts a go-git error into a *GitError of the same kinds
+// the git binary's output is classified into.
+func builtinError(ctx context.Context, command string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var netErr net.Error
+	kind := classifyGitOutput(err.Error())
+	switch {
+	case errors.Is(err, transport.ErrRepositoryNotFound), errors.Is(err, transport.ErrEmptyRemoteRepository), errors.Is(err, os.ErrNotExist):
+		kind = ErrRepoNotFound
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed), errors.Is(err, transport.ErrInvalidAuthMethod):
+		kind = ErrAuthRequired
+	case isUnknownRef(err):
+		kind = ErrUnknownRef
+	case errors.As(err, &netErr):
+		kind = ErrNetwork
+	}
+	return &GitError{Command: command, Kind: kind, Output: err.Error(), Err: err}
+}
@@ -0,0 +1,145 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBuiltinTestRepo makes a repo with two commits on main, a "dev" branch
+// and a "v1" tag on the first commit, and returns it with the first commit.
+func newBuiltinTestRepo(t *testing.T) (string, string) {
+	t.Helper()
+	repo := filepath.Join(t.TempDir(), "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(body string) {
+		if err := os.WriteFile(filepath.Join(repo, "VERSION"), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	write("1")
+	git("add", ".")
+	git("commit", "-qm", "one")
+	first := git("rev-parse", "HEAD")
+	git("tag", "v1")
+	git("branch", "dev")
+	write("2")
+	git("commit", "-qam", "two")
+	return repo, first
+}
+
+func TestBuiltinClone_WithoutGitBinary(t *testing.T) {
+	repo, first := newBuiltinTestRepo(t)
+	t.Setenv("PATH", "")
+	if b, _ := BackendFor(BackendAuto); b.Name() != BackendBuiltin {
+		t.Fatalf("expected auto to pick builtin without git, got %s", b.Name())
+	}
+
+	cases := []struct {
+		name    string
+		url     string
+		ref     string
+		version string
+	}{
+		{"local path, default branch", repo, "", "2"},
+		{"file URL, branch", "file://" + repo, "dev", "1"},
+		{"tag", repo, "v1", "1"},
+		{"commit", repo, first, "1"},
+	}
+	for i, tc := range cases {
+		dest := filepath.Join(t.TempDir(), string(rune('a'+i)))
+		if err := Builtin.Clone(context.Background(), tc.url, dest, CloneOptions{Ref: tc.ref}); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if b, err := os.ReadFile(filepath.Join(dest, "VERSION")); err != nil || string(b) != tc.version {
+			t.Fatalf("%s: VERSION=%q err=%v", tc.name, b, err)
+		}
+		if tc.ref == first {
+			if head, err := Builtin.HeadCommit(dest); err != nil || head != first {
+				t.Fatalf("%s: head=%s err=%v", tc.name, head, err)
+			}
+		}
+	}
+}
+
+func TestBuiltinClone_ReturnsTypedGitErrors(t *testing.T) {
+	repo, _ := newBuiltinTestRepo(t)
+	cases := []struct {
+		url  string
+		ref  string
+		want error
+	}{
+		{filepath.Join(filepath.Dir(repo), "missing"), "", ErrRepoNotFound},
+		{repo, "no-such-branch", ErrUnknownRef},
+	}
+	for _, tc := range cases {
+		err := Builtin.Clone(context.Background(), tc.url, filepath.Join(t.TempDir(), "clone"), CloneOptions{Ref: tc.ref})
+		var gitErr *GitError
+		if !errors.As(err, &gitErr) || !errors.Is(err, tc.want) {
+			t.Fatalf("%s@%s: expected %v, got %v", tc.url, tc.ref, tc.want, err)
+		}
+	}
+}
+
+func TestBackendFor(t *testing.T) {
+	for name, want := range map[string]string{"git": BackendExec, "BUILTIN": BackendBuiltin} {
+		b, err := BackendFor(name)
+		if err != nil || b.Name() != want {
+			t.Fatalf("BackendFor(%q) = %v, %v", name, b, err)
+		}
+	}
+	if _, err := BackendFor("libgit2"); err == nil {
+		t.Fatalf("expected unknown backend error")
+	}
+}
+
+func TestRemoteCommit_ResolvesRefsWithBothBackends(t *testing.T) {
+	repo, first := newBuiltinTestRepo(t)
+	out, err := exec.Command("git", "-C", repo, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := strings.TrimSpace(string(out))
+	if out, err := exec.Command("git", "-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "tag", "-a", "v1-annotated", "-m", "v1", first).CombinedOutput(); err != nil {
+		t.Fatalf("tag: %v\n%s", err, out)
+	}
+
+	cases := []struct {
+		ref  string
+		want string
+	}{
+		{"", head},
+		{"dev", first},
+		{"v1", first},
+		{"v1-annotated", first},
+		{first, first},
+	}
+	for _, b := range []Backend{Exec, Builtin} {
+		for _, tc := range cases {
+			got, err := b.RemoteCommit(context.Background(), "file://"+repo, tc.ref, RemoteOptions{})
+			if err != nil || got != tc.want {
+				t.Fatalf("%s %q: got %s err=%v, want %s", b.Name(), tc.ref, got, err, tc.want)
+			}
+		}
+		if _, err := b.RemoteCommit(context.Background(), "file://"+repo, "no-such-ref", RemoteOptions{}); !errors.Is(err, ErrUnknownRef) {
+			t.Fatalf("%s: expected ErrUnknownRef, got %v", b.Name(), err)
+		}
+	}
+}
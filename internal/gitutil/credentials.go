@@ -0,0 +1,37 @@
+package gitutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Token is an HTTPS access token for one git host, e.g. a GitHub token for
+// "github.com".
+type Token struct {
+	Host  string
+	Value string
+}
+
+// credentialConfig returns `-c` options installing a credential helper per
+// token host, plus the environment the helpers read the tokens from. Tokens
+// never appear in URLs or on the command line, and other hosts keep the
+// user's own helpers.
+func credentialConfig(tokens []Token) ([]string, []string) {
+	var args []string
+	var env []string
+	for i, t := range tokens {
+		host := strings.ToLower(strings.TrimSpace(t.Host))
+		value := strings.TrimSpace(t.Value)
+		if host == "" || value == "" {
+			continue
+		}
+		name := fmt.Sprintf("SKULLS_GIT_TOKEN_%d", i)
+		key := "credential.https://" + host + ".helper"
+		helper := `!f() { test "$1" = get && echo username=x-access-token && echo "password=$` + name + `"; }; f`
+		// The empty value resets helpers inherited from the user's config for
+		// this host, so ours is the one asked.
+		args = append(args, "-c", key+"=", "-c", key+"="+helper)
+		env = append(env, name+"="+value)
+	}
+	return args, env
+}
@@ -0,0 +1,68 @@
+package gitutil
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCredentialConfig_AnswersOnlyForTokenHost(t *testing.T) {
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+
+	args, env := credentialConfig([]Token{{Host: "GitHub.com", Value: "s3cret"}})
+	for _, a := range args {
+		if strings.Contains(a, "s3cret") {
+			t.Fatalf("token leaked into args: %q", a)
+		}
+	}
+
+	fill := func(host string) string {
+		t.Helper()
+		cmd := exec.Command("git", append(args, "credential", "fill")...)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		_ = cmd.Run()
+		return out.String()
+	}
+
+	if got := fill("github.com"); !strings.Contains(got, "username=x-access-token") || !strings.Contains(got, "password=s3cret") {
+		t.Fatalf("expected token for github.com, got %q", got)
+	}
+	if got := fill("gitlab.com"); strings.Contains(got, "s3cret") {
+		t.Fatalf("token sent to another host: %q", got)
+	}
+}
+
+func TestRemoteCommit_NonInteractiveUsesSSHBatchMode(t *testing.T) {
+	bin := t.TempDir()
+	logFile := filepath.Join(bin, "ssh-args")
+	script := "#!/bin/sh\necho \"$@\" > " + logFile + "\nexit 255\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	for _, name := range []string{"GIT_SSH_COMMAND", "GIT_SSH"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	_, err := RemoteCommit(context.Background(), "ssh://git@example.com/acme/skills.git", "", RemoteOptions{NonInteractive: true})
+	if err == nil {
+		t.Fatal("expected ls-remote to fail")
+	}
+	args, readErr := os.ReadFile(logFile)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if !strings.Contains(string(args), "BatchMode=yes") {
+		t.Fatalf("expected ssh to run in batch mode, got %q", args)
+	}
+}
@@ -0,0 +1,180 @@
+package gitutil
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Failure kinds a GitError can match via errors.Is.
+var (
+	ErrRepoNotFound = errors.New("repository not found")
+	ErrAuthRequired = errors.New("authentication required")
+	ErrNetwork      = errors.New("network unreachable")
+	ErrUnknownRef   = errors.New("unknown ref")
+)
+
+// maxGitOutput is how much of git's stderr a GitError keeps (the end of it).
+const maxGitOutput = 8 << 10
+
+// GitError is returned when a git command exits with an error. It keeps the
+// tail of git's stderr, which is otherwise often discarded (e.g. behind a
+// TUI), and classifies the common failures.
+type GitError struct {
+	// Command is the git subcommand, e.g. "clone".
+	Command string
+	// Kind is one of the Err* failure kinds above, or nil if unrecognized.
+	Kind error
+	// Output is the end of git's stderr.
+	Output string
+	// Err is the error from running git, usually an *exec.ExitError.
+	Err error
+}
+
+func (e *GitError) Error() string {
+	msg := "git " + e.Command + " failed"
+	switch {
+	case e.Kind != nil:
+		return msg + ": " + e.Kind.Error()
+	case lastLine(e.Output) != "":
+		return msg + ": " + strings.TrimPrefix(lastLine(e.Output), "fatal: ")
+	default:
+		return msg + ": " + e.Err.Error()
+	}
+}
+
+func (e *GitError) Is(target error) bool { return e.Kind != nil && target == e.Kind }
+
+func (e *GitError) Unwrap() error { return e.Err }
+
+// Hint explains the failure and what to try, or returns "" for unrecognized
+// failures.
+func (e *GitError) Hint() string {
+	switch e.Kind {
+	case ErrRepoNotFound:
+		return "The repository doesn't exist, or it's private and the request wasn't authenticated. Check the source for typos."
+	case ErrAuthRequired:
+		return "The repository needs credentials. Set GITHUB_TOKEN (or run `gh auth login`), or configure a git credential helper or SSH key for the host."
+	case ErrNetwork:
+		return "Couldn't reach the git host. Check your network connection, proxy settings and the host name."
+	case ErrUnknownRef:
+		return "The branch, tag or commit doesn't exist in the repository. Check --ref."
+	}
+	return ""
+}
+
+// Tail returns up to the last n non-empty lines of git's output.
+func (e *GitError) Tail(n int) []string {
+	var lines []string
+	for _, l := range strings.Split(e.Output, "\n") {
+		if l = strings.TrimRight(l, "\r "); strings.TrimSpace(l) != "" {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// gitFailurePatterns map substrings of git's lowercased stderr to failure
+// kinds. Order matters: the first match wins, and "not found" messages are
+// more specific than the transport errors that often accompany them.
+var gitFailurePatterns = []struct {
+	kind     error
+	patterns []string
+}{
+	{ErrUnknownRef, []string{
+		"remote branch", // "Remote branch x not found in upstream origin"
+		"couldn't find remote ref",
+		"not our ref",
+		"invalid reference",
+		"did not match any file(s) known to git",
+	}},
+	{ErrRepoNotFound, []string{
+		"repository not found",
+		"does not appear to be a git repository",
+		"returned error: 404",
+	}},
+	{ErrAuthRequired, []string{
+		"authentication failed",
+		"could not read username",
+		"could not read password",
+		"terminal prompts disabled",
+		"permission denied (publickey",
+		"invalid username or password",
+		"returned error: 401",
+		"returned error: 403",
+	}},
+	{ErrNetwork, []string{
+		"could not resolve host",
+		"could not resolve hostname",
+		"failed to connect",
+		"connection refused",
+		"connection timed out",
+		"operation timed out",
+		"network is unreachable",
+		"no route to host",
+		"ssl certificate problem",
+	}},
+}
+
+// gitFailureRegexps add anchored patterns to a kind, for messages whose
+// substrings are too broad on their own: "repository '/x' does not exist"
+// but not "path 'x' does not exist in 'HEAD'".
+var gitFailureRegexps = map[error]*regexp.Regexp{
+	ErrRepoNotFound: regexp.MustCompile(`repository '[^']*' does not exist`),
+}
+
+func classifyGitOutput(output string) error {
+	lower := strings.ToLower(output)
+	for _, f := range gitFailurePatterns {
+		for _, p := range f.patterns {
+			if strings.Contains(lower, p) {
+				return f.kind
+			}
+		}
+		if re := gitFailureRegexps[f.kind]; re != nil && re.MatchString(lower) {
+			return f.kind
+		}
+	}
+	return nil
+}
+
+// gitSubcommand returns the first argument that isn't a global option.
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "-c" || a == "-C":
+			i++
+		case strings.HasPrefix(a, "-"):
+		default:
+			return a
+		}
+	}
+	return ""
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(s)
+}
+
+// tailBuffer is an io.Writer keeping only the last max bytes written.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string { return string(t.buf) }
@@ -0,0 +1,78 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClone_ReturnsTypedGitErrors(t *testing.T) {
+	tmp := t.TempDir()
+	repo := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"commit", "-q", "--allow-empty", "-m", "init"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	cases := []struct {
+		name string
+		url  string
+		ref  string
+		want error
+	}{
+		{"missing repo", filepath.Join(tmp, "missing"), "", ErrRepoNotFound},
+		{"missing branch", "file://" + repo, "no-such-branch", ErrUnknownRef},
+		{"missing commit", "file://" + repo, strings.Repeat("a", 40), ErrUnknownRef},
+	}
+	for i, tc := range cases {
+		dest := filepath.Join(tmp, "clone", string(rune('a'+i)))
+		err := Clone(context.Background(), tc.url, dest, CloneOptions{Ref: tc.ref, Stdout: io.Discard, Stderr: io.Discard})
+		var gitErr *GitError
+		if !errors.As(err, &gitErr) || !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+		if gitErr.Hint() == "" || len(gitErr.Tail(5)) == 0 {
+			t.Fatalf("%s: expected hint and output, got %+v", tc.name, gitErr)
+		}
+	}
+}
+
+func TestClassifyGitOutput(t *testing.T) {
+	cases := map[string]error{
+		"remote: Repository not found.\nfatal: repository 'https://github.com/o/r.git/' not found":                 ErrRepoNotFound,
+		"fatal: could not read Username for 'https://github.com': terminal prompts disabled":                       ErrAuthRequired,
+		"git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.":            ErrAuthRequired,
+		"fatal: unable to access 'https://example.invalid/r.git/': Could not resolve host: example.invalid":        ErrNetwork,
+		"warning: Could not find remote branch v9 to clone.\nfatal: Remote branch v9 not found in upstream origin": ErrUnknownRef,
+		"fatal: repository '/tmp/missing' does not exist":                                                          ErrRepoNotFound,
+		"fatal: path 'skills/x' does not exist in 'HEAD'":                                                          nil,
+		"fatal: something unexpected": nil,
+	}
+	for output, want := range cases {
+		if got := classifyGitOutput(output); got != want {
+			t.Fatalf("classifyGitOutput(%q) = %v, want %v", output, got, want)
+		}
+	}
+}
+
+func TestGitError_MessageFallsBackToLastLine(t *testing.T) {
+	e := &GitError{Command: "clone", Output: "Cloning into 'x'...\nfatal: something unexpected\n", Err: errors.New("exit status 128")}
+	if got := e.Error(); got != "git clone failed: something unexpected" {
+		t.Fatalf("Error() = %q", got)
+	}
+	if got := gitSubcommand([]string{"-c", "credential.helper=", "-c", "advice.detachedHead=false", "clone", "--depth", "1"}); got != "clone" {
+		t.Fatalf("gitSubcommand = %q", got)
+	}
+}
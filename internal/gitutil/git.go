@@ -1,17 +1,25 @@
 package gitutil
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // NormalizeSourceToGitURL accepts:
 // - owner/repo (GitHub shorthand)
+// - host/owner/repo (shorthand for other hosts, e.g. GitHub Enterprise)
+// - gitlab:owner/repo, bitbucket:owner/repo and gitea:host/owner/repo
 // - any URL-like git remote (https://..., git@..., file:///...)
 // - local path to a git repo
 func NormalizeSourceToGitURL(source string) (string, error) {
@@ -20,6 +28,10 @@ func NormalizeSourceToGitURL(source string) (string, error) {
 		return "", errors.New("source is required")
 	}
 
+	if u, ok, err := forgeShorthandURL(source); ok {
+		return u, err
+	}
+
 	// URL-ish
 	if strings.Contains(source, "://") || strings.HasPrefix(source, "git@") {
 		return source, nil
@@ -42,20 +54,280 @@ func NormalizeSourceToGitURL(source string) (string, error) {
 		return fmt.Sprintf("https://github.com/%s/%s.git", parts[0], parts[1]), nil
 	}
 
+	// host/owner/repo shorthand
+	if len(parts) == 3 && IsHostname(parts[0]) && parts[1] != "" && parts[2] != "" {
+		return fmt.Sprintf("https://%s/%s/%s.git", parts[0], parts[1], strings.TrimSuffix(parts[2], ".git")), nil
+	}
+
 	return "", fmt.Errorf("unsupported source format: %q", source)
 }
 
+// forgeShorthands are the prefix:path source shorthands for hosts other than
+// GitHub. An empty host means the host is the first path segment.
+var forgeShorthands = []struct {
+	prefix    string
+	host      string
+	segments  int
+	subgroups bool // GitLab allows gitlab:group/sub/repo
+}{
+	{prefix: "gitlab:", host: "gitlab.com", segments: 2, subgroups: true},
+	{prefix: "bitbucket:", host: "bitbucket.org", segments: 2},
+	{prefix: "gitea:", segments: 3},
+}
+
+// forgeShorthandURL expands source if it starts with one of the
+// forgeShorthands prefixes; ok reports whether it did, err a malformed path.
+func forgeShorthandURL(source string) (u string, ok bool, err error) {
+	for _, f := range forgeShorthands {
+		rest, found := strings.CutPrefix(source, f.prefix)
+		if !found {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(strings.Trim(rest, "/"), ".git"), "/")
+		if len(parts) < f.segments || (len(parts) > f.segments && !f.subgroups) || slices.Contains(parts, "") {
+			return "", true, fmt.Errorf("unsupported source format: %q", source)
+		}
+		host := f.host
+		if host == "" {
+			host, parts = parts[0], parts[1:]
+		}
+		return fmt.Sprintf("https://%s/%s.git", host, strings.Join(parts, "/")), true, nil
+	}
+	return "", false, nil
+}
+
+// IsHostname reports whether the first segment of a host/owner/repo shorthand
+// names a host rather than an owner: hosts have a dot or a port.
+func IsHostname(s string) bool {
+	return s != "" && strings.ContainsAny(s, ".:") && !strings.HasPrefix(s, ".")
+}
+
 func looksLikePath(s string) bool {
 	return strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../") || strings.HasPrefix(s, "/") || s == "." || s == ".."
 }
 
+var commitSHARe = regexp.MustCompile(`^[0-9a-fA-F]{40}([0-9a-fA-F]{24})?$`)
+
+// CloneOptions configures Clone.
+type CloneOptions struct {
+	// Ref is a branch, tag or full commit SHA to check out. Empty means the
+	// remote's default branch.
+	Ref string
+
+	// Tokens authenticate HTTPS clones through a credential helper; see Token.
+	Tokens []Token
+
+	// SparsePaths, if set, limits the checkout to these directories and
+	// fetches blobs only for them (--filter=blob:none plus a cone-mode
+	// sparse-checkout).
+	SparsePaths []string
+
+	// Progress, if set, receives git's transfer progress (see Progress).
+	Progress func(Progress)
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
 func CloneShallow(url string, dest string) error {
 	return CloneShallowTo(url, dest, os.Stdout, os.Stderr)
 }
 
 func CloneShallowTo(url string, dest string, stdout io.Writer, stderr io.Writer) error {
-	cmd := exec.Command("git", "clone", "--depth", "1", url, dest)
+	return Clone(context.Background(), url, dest, CloneOptions{Stdout: stdout, Stderr: stderr})
+}
+
+// Clone makes a shallow clone of url at opts.Ref into dest. Cancelling ctx
+// kills git; dest may then be left partially written.
+//
+// Branches and tags use `git clone --branch`; commit SHAs can't be cloned
+// directly, so they're fetched into a fresh repository instead.
+func Clone(ctx context.Context, url string, dest string, opts CloneOptions) error {
+	ref := strings.TrimSpace(opts.Ref)
+	if commitSHARe.MatchString(ref) {
+		return fetchCommit(ctx, url, dest, ref, opts)
+	}
+
+	creds, env := credentialConfig(opts.Tokens)
+	stderr, progressArgs := opts.progressStderr()
+	args := append(creds, "-c", "advice.detachedHead=false", "clone", "--depth", "1")
+	args = append(args, progressArgs...)
+	if len(opts.SparsePaths) > 0 {
+		args = append(args, "--filter=blob:none", "--no-checkout")
+	}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, url, dest)
+	if err := runGitEnv(ctx, "", env, opts.Stdout, stderr, args...); err != nil {
+		return err
+	}
+	if len(opts.SparsePaths) == 0 {
+		return nil
+	}
+	return sparseCheckout(ctx, dest, "", opts)
+}
+
+// sparseCheckout restricts dest to opts.SparsePaths and checks out rev (HEAD
+// when empty), fetching the missing blobs.
+func sparseCheckout(ctx context.Context, dest string, rev string, opts CloneOptions) error {
+	if err := runGit(ctx, dest, opts.Stdout, opts.Stderr, "sparse-checkout", "init", "--cone"); err != nil {
+		return err
+	}
+	if err := runGit(ctx, dest, opts.Stdout, opts.Stderr, append([]string{"sparse-checkout", "set"}, opts.SparsePaths...)...); err != nil {
+		return err
+	}
+	creds, env := credentialConfig(opts.Tokens)
+	args := append(creds, "-c", "advice.detachedHead=false", "checkout", "-q")
+	if rev != "" {
+		args = append(args, rev)
+	}
+	return runGitEnv(ctx, dest, env, opts.Stdout, opts.Stderr, args...)
+}
+
+func fetchCommit(ctx context.Context, url string, dest string, sha string, opts CloneOptions) error {
+	if err := runGit(ctx, "", opts.Stdout, opts.Stderr, "init", "-q", dest); err != nil {
+		return err
+	}
+	if err := runGit(ctx, dest, opts.Stdout, opts.Stderr, "remote", "add", "origin", url); err != nil {
+		return err
+	}
+	creds, env := credentialConfig(opts.Tokens)
+	stderr, progressArgs := opts.progressStderr()
+	args := append(creds, "fetch", "--depth", "1")
+	args = append(args, progressArgs...)
+	if len(opts.SparsePaths) > 0 {
+		args = append(args, "--filter=blob:none")
+	}
+	if err := runGitEnv(ctx, dest, env, opts.Stdout, stderr, append(args, "origin", sha)...); err != nil {
+		return err
+	}
+	if len(opts.SparsePaths) > 0 {
+		return sparseCheckout(ctx, dest, "FETCH_HEAD", opts)
+	}
+	return runGit(ctx, dest, opts.Stdout, opts.Stderr, "-c", "advice.detachedHead=false", "checkout", "-q", "FETCH_HEAD")
+}
+
+// HeadCommit returns the commit SHA checked out in repoDir.
+func HeadCommit(repoDir string) (string, error) {
+	out, err := exec.Command("git", "-C", repoDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RemoteOptions configures RemoteCommit.
+type RemoteOptions struct {
+	// Tokens authenticate HTTPS remotes through a credential helper; see Token.
+	Tokens []Token
+
+	// NonInteractive makes git fail instead of prompting for credentials or
+	// SSH passphrases, for checks that run in the background.
+	NonInteractive bool
+}
+
+// nonInteractiveEnv keeps git and ssh from prompting on the terminal.
+var nonInteractiveEnv = []string{"GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND=ssh -o BatchMode=yes"}
+
+// RemoteCommit returns the commit ref (a branch or tag; empty means the
+// default branch) currently points to on url, without cloning it. A commit
+// SHA ref is returned as is.
+func RemoteCommit(ctx context.Context, url string, ref string, opts RemoteOptions) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if commitSHARe.MatchString(ref) {
+		return ref, nil
+	}
+	// In order of preference: a branch over a tag, like clone --branch,
+	// and an annotated tag's commit over the tag object.
+	patterns := []string{"HEAD"}
+	if ref != "" {
+		patterns = []string{"refs/heads/" + ref, "refs/tags/" + ref + "^{}", "refs/tags/" + ref}
+	}
+	creds, env := credentialConfig(opts.Tokens)
+	if opts.NonInteractive {
+		env = append(env, nonInteractiveEnv...)
+	}
+	out := &bytes.Buffer{}
+	if err := runGitEnv(ctx, "", env, out, nil, append(append(creds, "ls-remote", url), patterns...)...); err != nil {
+		return "", err
+	}
+	refs := map[string]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if sha, name, ok := strings.Cut(strings.TrimSpace(line), "\t"); ok {
+			refs[name] = sha
+		}
+	}
+	for _, name := range patterns {
+		if sha, ok := refs[name]; ok {
+			return sha, nil
+		}
+	}
+	return "", &GitError{Command: "ls-remote", Kind: ErrUnknownRef, Output: "couldn't find remote ref " + ref, Err: errors.New("no matching ref")}
+}
+
+func runGit(ctx context.Context, dir string, stdout io.Writer, stderr io.Writer, args ...string) error {
+	return runGitEnv(ctx, dir, nil, stdout, stderr, args...)
+}
+
+// runGitEnv runs git with env added to the current environment. Failures
+// are returned as a *GitError carrying the end of git's stderr, which is
+// still passed on to stderr.
+func runGitEnv(ctx context.Context, dir string, env []string, stdout io.Writer, stderr io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	output := &tailBuffer{max: maxGitOutput}
 	cmd.Stdout = stdout
-	cmd.Stderr = stderr
-	return cmd.Run()
+	cmd.Stderr = output
+	if stderr != nil {
+		cmd.Stderr = io.MultiWriter(stderr, output)
+	}
+	// git's helpers (git-remote-https, ...) can outlive a killed git and
+	// hold the output pipes; don't wait on them for long.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		out := output.String()
+		return &GitError{Command: gitSubcommand(args), Kind: classifyGitOutput(out), Output: out, Err: err}
+	}
+	return nil
+}
+
+var gitVersionRe = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// Version returns the installed git's version as reported by `git --version`
+// (e.g. "2.39.5"), or an error if git isn't on PATH.
+func Version() (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", err
+	}
+	out, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	v := gitVersionRe.FindString(string(out))
+	if v == "" {
+		return "", fmt.Errorf("unexpected git --version output: %q", strings.TrimSpace(string(out)))
+	}
+	return v, nil
+}
+
+// VersionAtLeast reports whether version (as returned by Version) is at least
+// major.minor.
+func VersionAtLeast(version string, major int, minor int) bool {
+	m := gitVersionRe.FindStringSubmatch(version)
+	if m == nil {
+		return false
+	}
+	gotMajor, _ := strconv.Atoi(m[1])
+	gotMinor, _ := strconv.Atoi(m[2])
+	if gotMajor != major {
+		return gotMajor > major
+	}
+	return gotMinor >= minor
 }
@@ -0,0 +1,29 @@
+package gitutil
+
+import "testing"
+
+func TestNormalizeSourceToGitURL_Shorthands(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"obra/superpowers", "https://github.com/obra/superpowers.git"},
+		{"github.acme.com/team/skills", "https://github.acme.com/team/skills.git"},
+		{"gitlab:group/repo", "https://gitlab.com/group/repo.git"},
+		{"gitlab:group/sub/repo", "https://gitlab.com/group/sub/repo.git"},
+		{"bitbucket:acme/skills", "https://bitbucket.org/acme/skills.git"},
+		{"gitea:codeberg.org/acme/skills", "https://codeberg.org/acme/skills.git"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeSourceToGitURL(tt.source)
+		if err != nil || got != tt.want {
+			t.Fatalf("%s: got %q, %v want %q", tt.source, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"gitlab:repo", "bitbucket:a/b/c", "gitea:codeberg.org/acme", "gitea:host/a//b"} {
+		if got, err := NormalizeSourceToGitURL(bad); err == nil {
+			t.Fatalf("%s: expected error, got %q", bad, got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package gitutil
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// Progress is one update from git's --progress output, e.g.
+// "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s".
+type Progress struct {
+	// Phase is what git is doing: "Counting objects", "Receiving objects",
+	// "Resolving deltas", "Updating files", ...
+	Phase   string
+	Percent int
+	Current int
+	Total   int
+	// Bytes is the amount received so far; only "Receiving objects" reports it.
+	Bytes int64
+}
+
+var progressLineRe = regexp.MustCompile(`^(?:remote: )?([A-Za-z][A-Za-z ]*):\s+(\d+)% \((\d+)/(\d+)\)(?:, ([\d.]+) (bytes|KiB|MiB|GiB))?`)
+
+var byteUnits = map[string]float64{"bytes": 1, "KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30}
+
+// parseProgressLine parses one line (or \r-separated update) of git's
+// progress output.
+func parseProgressLine(line string) (Progress, bool) {
+	m := progressLineRe.FindStringSubmatch(line)
+	if m == nil {
+		return Progress{}, false
+	}
+	p := Progress{Phase: m[1]}
+	p.Percent, _ = strconv.Atoi(m[2])
+	p.Current, _ = strconv.Atoi(m[3])
+	p.Total, _ = strconv.Atoi(m[4])
+	if m[5] != "" {
+		n, _ := strconv.ParseFloat(m[5], 64)
+		p.Bytes = int64(n * byteUnits[m[6]])
+	}
+	return p, true
+}
+
+// progressWriter passes git's stderr through to w and calls fn whenever the
+// phase or percentage changes. git redraws progress lines with \r, so both
+// \r and \n end an update.
+type progressWriter struct {
+	w    io.Writer
+	fn   func(Progress)
+	buf  []byte
+	last Progress
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	pw.buf = append(pw.buf, p...)
+	for {
+		i := bytes.IndexAny(pw.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		if prog, ok := parseProgressLine(string(pw.buf[:i])); ok && (prog.Phase != pw.last.Phase || prog.Percent != pw.last.Percent) {
+			pw.last = prog
+			pw.fn(prog)
+		}
+		pw.buf = pw.buf[i+1:]
+	}
+	if pw.w == nil {
+		return len(p), nil
+	}
+	return pw.w.Write(p)
+}
+
+// progressStderr returns where git's stderr goes for opts, and the extra
+// arguments asking git to report progress when opts.Progress is set.
+func (opts CloneOptions) progressStderr() (io.Writer, []string) {
+	if opts.Progress == nil {
+		return opts.Stderr, nil
+	}
+	return &progressWriter{w: opts.Stderr, fn: opts.Progress}, []string{"--progress"}
+}
+
+// FormatBytes renders n like git does ("1.20 MiB").
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return strconv.FormatFloat(float64(n)/(1<<30), 'f', 2, 64) + " GiB"
+	case n >= 1<<20:
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 2, 64) + " MiB"
+	case n >= 1<<10:
+		return strconv.FormatFloat(float64(n)/(1<<10), 'f', 2, 64) + " KiB"
+	}
+	return strconv.FormatInt(n, 10) + " bytes"
+}
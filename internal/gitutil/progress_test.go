@@ -0,0 +1,68 @@
+package gitutil
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	cases := map[string]Progress{
+		"Receiving objects:  45% (450/1000), 1.50 MiB | 2.00 MiB/s": {Phase: "Receiving objects", Percent: 45, Current: 450, Total: 1000, Bytes: 3 << 19},
+		"remote: Counting objects: 100% (5/5), done.":               {Phase: "Counting objects", Percent: 100, Current: 5, Total: 5},
+		"Resolving deltas:   0% (0/12)":                             {Phase: "Resolving deltas", Percent: 0, Current: 0, Total: 12},
+	}
+	for line, want := range cases {
+		got, ok := parseProgressLine(line)
+		if !ok || got != want {
+			t.Fatalf("parseProgressLine(%q) = %+v, %v; want %+v", line, got, ok, want)
+		}
+	}
+	if _, ok := parseProgressLine("Cloning into 'repo'..."); ok {
+		t.Fatalf("expected non-progress line to be ignored")
+	}
+}
+
+func TestProgressWriter_SplitsCarriageReturnUpdates(t *testing.T) {
+	var got []Progress
+	pw := &progressWriter{fn: func(p Progress) { got = append(got, p) }}
+	for _, chunk := range []string{"Receiving objects:  10% (1/10)\rReceiving obj", "ects:  10% (1/10)\rReceiving objects: 100% (10/10), 2.00 KiB | 1 KiB/s, done.\n"} {
+		if _, err := pw.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 2 || got[1].Percent != 100 || got[1].Bytes != 2048 {
+		t.Fatalf("progress=%+v", got)
+	}
+}
+
+func TestClone_ReportsProgress(t *testing.T) {
+	tmp := t.TempDir()
+	repo := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("hi"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-qm", "init"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	phases := map[string]int{}
+	opts := CloneOptions{Stdout: io.Discard, Stderr: io.Discard, Progress: func(p Progress) { phases[p.Phase] = p.Percent }}
+	if err := Clone(context.Background(), "file://"+repo, filepath.Join(tmp, "clone"), opts); err != nil {
+		t.Fatal(err)
+	}
+	if phases["Receiving objects"] != 100 {
+		t.Fatalf("expected receiving progress to reach 100%%, got %v", phases)
+	}
+}
@@ -0,0 +1,60 @@
+package gitutil
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Remote is the host/owner/repo breakdown of a normalized git remote.
+// Local paths have an empty Host and Owner.
+type Remote struct {
+	Host  string
+	Owner string
+	Repo  string
+}
+
+// ParseRemote splits a URL returned by NormalizeSourceToGitURL into its parts.
+// It understands scheme URLs (https://, ssh://, file://), scp-like SSH remotes
+// (git@host:owner/repo.git) and plain local paths.
+func ParseRemote(remote string) Remote {
+	s := strings.TrimSpace(remote)
+
+	var host, p string
+	switch {
+	case strings.Contains(s, "://"):
+		u, err := url.Parse(s)
+		if err != nil {
+			return Remote{}
+		}
+		if u.Scheme == "file" {
+			return Remote{}
+		}
+		host = u.Hostname()
+		p = u.Path
+	case isSCPLike(s):
+		at := strings.Index(s, "@")
+		colon := strings.Index(s, ":")
+		host = s[at+1 : colon]
+		p = s[colon+1:]
+	default:
+		return Remote{}
+	}
+
+	out := Remote{Host: strings.ToLower(host)}
+	p = strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+	if p == "" {
+		return out
+	}
+	parts := strings.Split(p, "/")
+	out.Owner = parts[0]
+	if len(parts) > 1 {
+		out.Repo = strings.Join(parts[1:], "/")
+	}
+	return out
+}
+
+func isSCPLike(s string) bool {
+	at := strings.Index(s, "@")
+	colon := strings.Index(s, ":")
+	return at > 0 && colon > at+1 && !strings.Contains(s[:colon], "/")
+}
@@ -0,0 +1,114 @@
+package gitutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// ErrBadSignature is matched (via errors.Is) when a commit or tag is unsigned
+// or its signature isn't trusted.
+var ErrBadSignature = errors.New("signature verification failed")
+
+// VerifyOptions selects the trusted keys used to check signatures. Empty
+// fields fall back to the user's git/GnuPG configuration.
+type VerifyOptions struct {
+	// AllowedSignersFile is an SSH allowed-signers file (gpg.ssh.allowedSignersFile).
+	AllowedSignersFile string
+	// GPGHome is a GnuPG home directory holding the trusted keyring.
+	GPGHome string
+}
+
+// Signature describes a verified signature.
+type Signature struct {
+	// Object is what was verified, e.g. "tag v1.2.0" or "commit 1a2b3c…".
+	Object string
+	Signer string
+	Key    string
+}
+
+var (
+	sshGoodSigRe = regexp.MustCompile(`Good "git" signature for (.+?) with \S+ key (\S+)`)
+	gpgGoodSigRe = regexp.MustCompile(`(?m)^\[GNUPG:\] GOODSIG (\S+) (.+)$`)
+	gpgValidRe   = regexp.MustCompile(`(?m)^\[GNUPG:\] VALIDSIG (\S+)`)
+)
+
+// VerifyCheckout verifies the signature of what's checked out in repoDir.
+//
+// When ref names an annotated tag, the tag signature is verified with
+// `git verify-tag`; otherwise HEAD is verified with `git verify-commit`.
+func VerifyCheckout(repoDir string, ref string, opts VerifyOptions) (Signature, error) {
+	ref = strings.TrimSpace(ref)
+
+	var object string
+	var args []string
+	if ref != "" && isAnnotatedTag(repoDir, ref) {
+		object = "tag " + ref
+		args = []string{"verify-tag", "--raw", ref}
+	} else {
+		head, err := HeadCommit(repoDir)
+		if err != nil {
+			return Signature{}, err
+		}
+		object = "commit " + head
+		args = []string{"verify-commit", "--raw", "HEAD"}
+	}
+
+	if opts.AllowedSignersFile != "" {
+		args = append([]string{"-c", "gpg.ssh.allowedSignersFile=" + opts.AllowedSignersFile}, args...)
+	}
+	cmd := exec.Command("git", append([]string{"-C", repoDir}, args...)...)
+	if opts.GPGHome != "" {
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+opts.GPGHome)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return Signature{}, fmt.Errorf("%w for %s: %s", ErrBadSignature, object, signatureFailureReason(out.String()))
+	}
+
+	sig, ok := parseSignatureOutput(out.String())
+	if !ok {
+		return Signature{}, fmt.Errorf("%w for %s: unrecognized verification output", ErrBadSignature, object)
+	}
+	sig.Object = object
+	return sig, nil
+}
+
+func isAnnotatedTag(repoDir string, ref string) bool {
+	return exec.Command("git", "-C", repoDir, "rev-parse", "-q", "--verify", "refs/tags/"+ref+"^{tag}").Run() == nil
+}
+
+func parseSignatureOutput(out string) (Signature, bool) {
+	if m := sshGoodSigRe.FindStringSubmatch(out); m != nil {
+		return Signature{Signer: m[1], Key: m[2]}, true
+	}
+	if m := gpgGoodSigRe.FindStringSubmatch(out); m != nil {
+		sig := Signature{Signer: strings.TrimSpace(m[2]), Key: m[1]}
+		if v := gpgValidRe.FindStringSubmatch(out); v != nil {
+			sig.Key = v[1]
+		}
+		return sig, true
+	}
+	return Signature{}, false
+}
+
+func signatureFailureReason(out string) string {
+	lines := []string{}
+	for _, l := range strings.Split(out, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "[GNUPG:]") {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	if len(lines) == 0 {
+		return "no signature found"
+	}
+	return strings.Join(lines, "; ")
+}
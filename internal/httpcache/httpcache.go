@@ -0,0 +1,404 @@
+// Package httpcache is an on-disk HTTP cache for GET requests. It honors
+// Cache-Control (max-age, no-cache, no-store) and Expires for freshness,
+// keeps a separate entry per value of the Vary headers, and revalidates stale entries with
+// ETag/If-None-Match and Last-Modified/If-Modified-Since. Old entries are
+// pruned as new ones are written.
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusHeader is set on responses served by the cache: "hit" for a fresh
+// entry, "revalidated" after a 304, and "stale" when the network failed and
+// an old entry was served instead.
+const StatusHeader = "X-Skulls-Cache"
+
+// Defaults for Transport.MaxSize, Transport.MaxAge and
+// Transport.MaxEntrySize.
+const (
+	DefaultMaxSize      = 50 << 20
+	DefaultMaxAge       = 30 * 24 * time.Hour
+	DefaultMaxEntrySize = 4 << 20
+)
+
+// Transport is an http.RoundTripper that caches successful GET responses in
+// Dir.
+type Transport struct {
+	Dir string
+
+	// TTL, if positive, overrides the server's freshness lifetime: entries
+	// younger than TTL are served without contacting the server.
+	TTL time.Duration
+
+	// MaxSize and MaxAge bound the cache: entries written longer than MaxAge
+	// ago are removed, then the oldest ones until the rest fit in MaxSize
+	// bytes. Zero means DefaultMaxSize and DefaultMaxAge.
+	MaxSize int64
+	MaxAge  time.Duration
+
+	// MaxEntrySize is the largest body that is cached; bigger responses are
+	// passed through as they stream in. Zero means DefaultMaxEntrySize.
+	MaxEntrySize int64
+
+	// Base performs the actual requests. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	now func() time.Time
+}
+
+type entry struct {
+	URL      string      `json:"url"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+
+	// Vary holds the request's values of the headers the response varies
+	// on; the entry only serves requests with the same values.
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// New returns a Transport caching into dir.
+func New(dir string, ttl time.Duration) *Transport {
+	return &Transport{Dir: dir, TTL: ttl}
+}
+
+// Client returns an http.Client with the given timeout that caches into dir.
+func Client(dir string, ttl time.Duration, timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: New(dir, ttl)}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base().RoundTrip(req)
+	}
+
+	path, cached, hasCached := t.lookup(req)
+	if hasCached && t.fresh(cached) && !hasDirective(req.Header, "no-cache") {
+		return cached.response(req, "hit"), nil
+	}
+
+	outReq := req
+	if hasCached {
+		outReq = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+		if lm := cached.Header.Get("Last-Modified"); lm != "" {
+			outReq.Header.Set("If-Modified-Since", lm)
+		}
+	}
+
+	resp, err := t.base().RoundTrip(outReq)
+	if err != nil {
+		if hasCached && req.Context().Err() == nil {
+			return cached.response(req, "stale"), nil
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		_ = resp.Body.Close()
+		for k, v := range resp.Header {
+			if isRevalidationHeader(k) {
+				cached.Header[k] = v
+			}
+		}
+		cached.StoredAt = t.clock()
+		t.store(path, cached)
+		return cached.response(req, "revalidated"), nil
+	}
+
+	vary, ok := varyValues(req, resp.Header)
+	if resp.StatusCode != http.StatusOK || hasDirective(resp.Header, "no-store") || !ok {
+		return resp, nil
+	}
+
+	maxEntry := t.MaxEntrySize
+	if maxEntry <= 0 {
+		maxEntry = DefaultMaxEntrySize
+	}
+	if resp.ContentLength > maxEntry {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxEntry+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > maxEntry {
+		// Too big to cache: hand back what was read followed by the rest.
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	e := entry{URL: req.URL.String(), Status: resp.StatusCode, Header: resp.Header.Clone(), Body: body, StoredAt: t.clock(), Vary: vary}
+	if len(vary) > 0 {
+		// Each combination of Vary values gets its own entry; the URL's
+		// entry just lists the headers to key them by.
+		t.store(t.entryPath(req, nil), entry{URL: e.URL, Header: http.Header{}, StoredAt: e.StoredAt, Vary: vary})
+		path = t.entryPath(req, vary)
+	}
+	t.store(path, e)
+	t.prune()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// fresh reports whether e can be served without revalidation.
+func (t *Transport) fresh(e entry) bool {
+	age := t.clock().Sub(e.StoredAt)
+	if t.TTL > 0 {
+		return age < t.TTL
+	}
+	if hasDirective(e.Header, "no-cache") {
+		return false
+	}
+	if maxAge, ok := directiveSeconds(e.Header, "max-age"); ok {
+		return age < time.Duration(maxAge)*time.Second
+	}
+	if exp := e.Header.Get("Expires"); exp != "" {
+		if at, err := http.ParseTime(exp); err == nil {
+			return t.clock().Before(at)
+		}
+	}
+	return false
+}
+
+// varyValues returns req's values of the headers h varies on. A response
+// that varies on everything can't be cached.
+func varyValues(req *http.Request, h http.Header) (map[string]string, bool) {
+	var out map[string]string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return nil, false
+			}
+			if out == nil {
+				out = map[string]string{}
+			}
+			out[name] = req.Header.Get(name)
+		}
+	}
+	return out, true
+}
+
+// matches reports whether e may serve req, per the Vary headers it was
+// stored with.
+func (e entry) matches(req *http.Request) bool {
+	for name, v := range e.Vary {
+		if req.Header.Get(name) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup finds the entry for req: the URL's own entry or, when responses
+// for the URL vary, the one for req's values of the Vary headers.
+func (t *Transport) lookup(req *http.Request) (string, entry, bool) {
+	path := t.entryPath(req, nil)
+	e, ok := t.load(path)
+	if ok && e.Status == 0 && len(e.Vary) > 0 {
+		path = t.entryPath(req, e.Vary)
+		e, ok = t.load(path)
+	}
+	return path, e, ok && e.Status != 0 && e.matches(req)
+}
+
+// entryPath returns where the entry for req is stored, keyed by its URL,
+// credentials and its values of the vary headers, if any.
+func (t *Transport) entryPath(req *http.Request, vary map[string]string) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, req.URL.String())
+	// Don't share entries between credentials.
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		_, _ = io.WriteString(h, "\x00"+auth)
+	}
+	names := make([]string, 0, len(vary))
+	for name := range vary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = io.WriteString(h, "\x00"+name+"="+req.Header.Get(name))
+	}
+	return filepath.Join(t.Dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+func (t *Transport) load(path string) (entry, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return entry{}, false
+	}
+	var e entry
+	if err := json.Unmarshal(b, &e); err != nil || e.Header == nil {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// store writes e best-effort; a cache that can't be written just doesn't cache.
+func (t *Transport) store(path string, e entry) {
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(t.Dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(b)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// prune removes entries older than MaxAge, then the oldest ones until the
+// cache fits in MaxSize. Like store, it's best-effort.
+func (t *Transport) prune() {
+	maxSize, maxAge := t.MaxSize, t.MaxAge
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	des, err := os.ReadDir(t.Dir)
+	if err != nil {
+		return
+	}
+	type file struct {
+		path string
+		size int64
+		mod  time.Time
+	}
+	var files []file
+	// Modification times are real, whatever t.now says.
+	now := time.Now()
+	for _, de := range des {
+		if de.IsDir() || filepath.Ext(de.Name()) != ".json" {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		p := filepath.Join(t.Dir, de.Name())
+		if now.Sub(info.ModTime()) > maxAge {
+			_ = os.Remove(p)
+			continue
+		}
+		files = append(files, file{path: p, size: info.Size(), mod: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod.After(files[j].mod) })
+	var total int64
+	for _, f := range files {
+		total += f.size
+		if total > maxSize {
+			_ = os.Remove(f.path)
+		}
+	}
+}
+
+func (e entry) response(req *http.Request, status string) *http.Response {
+	h := e.Header.Clone()
+	h.Set(StatusHeader, status)
+	return &http.Response{
+		Status:        strconv.Itoa(e.Status) + " " + http.StatusText(e.Status),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func isRevalidationHeader(k string) bool {
+	switch http.CanonicalHeaderKey(k) {
+	case "Cache-Control", "Expires", "Etag", "Last-Modified", "Date":
+		return true
+	}
+	return false
+}
+
+func cacheControl(h http.Header) []string {
+	var out []string
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
+
+func hasDirective(h http.Header, name string) bool {
+	for _, d := range cacheControl(h) {
+		if d == name || strings.HasPrefix(d, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func directiveSeconds(h http.Header, name string) (int, bool) {
+	for _, d := range cacheControl(h) {
+		v, ok := strings.CutPrefix(d, name+"=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.Trim(v, `"`))
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		return n, true
+	}
+	return 0, false
+}
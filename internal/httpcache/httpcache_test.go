@@ -0,0 +1,262 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, c *http.Client, url string) (string, string) {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b), resp.Header.Get(StatusHeader)
+}
+
+func TestTransport_RevalidatesWithETag(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &Transport{Dir: t.TempDir(), Base: srv.Client().Transport}}
+
+	body, status := get(t, c, srv.URL)
+	if body != "hello" || status != "" {
+		t.Fatalf("first body=%q status=%q", body, status)
+	}
+	body, status = get(t, c, srv.URL)
+	if body != "hello" || status != "revalidated" {
+		t.Fatalf("second body=%q status=%q", body, status)
+	}
+	if hits != 2 {
+		t.Fatalf("expected a conditional request, hits=%d", hits)
+	}
+}
+
+func TestTransport_ServesFreshEntriesWithoutRequest(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		_, _ = w.Write([]byte("fresh"))
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	tr := &Transport{Dir: t.TempDir(), Base: srv.Client().Transport, now: func() time.Time { return now }}
+	c := &http.Client{Transport: tr}
+
+	get(t, c, srv.URL)
+	if body, status := get(t, c, srv.URL); body != "fresh" || status != "hit" {
+		t.Fatalf("body=%q status=%q", body, status)
+	}
+	if hits != 1 {
+		t.Fatalf("hits=%d", hits)
+	}
+
+	now = now.Add(2 * time.Minute)
+	get(t, c, srv.URL)
+	if hits != 2 {
+		t.Fatalf("expected refetch after max-age, hits=%d", hits)
+	}
+}
+
+func TestTransport_TTLOverridesServerHeaders(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write([]byte("x"))
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &Transport{Dir: t.TempDir(), TTL: time.Hour, Base: srv.Client().Transport}}
+	get(t, c, srv.URL)
+	if _, status := get(t, c, srv.URL); status != "hit" {
+		t.Fatalf("status=%q", status)
+	}
+	if hits != 1 {
+		t.Fatalf("hits=%d", hits)
+	}
+}
+
+func TestTransport_NoStoreAndErrorsAreNotCached(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &Transport{Dir: t.TempDir(), TTL: time.Hour, Base: srv.Client().Transport}}
+	get(t, c, srv.URL)
+	get(t, c, srv.URL)
+	get(t, c, srv.URL+"/missing")
+	get(t, c, srv.URL+"/missing")
+	if hits != 4 {
+		t.Fatalf("hits=%d", hits)
+	}
+}
+
+func TestTransport_OversizedBodiesAreNotCached(t *testing.T) {
+	hits := 0
+	big := strings.Repeat("x", 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path == "/chunked" {
+			// Flushing before writing leaves the length unknown.
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(big))
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &Transport{Dir: t.TempDir(), TTL: time.Hour, MaxEntrySize: 10, Base: srv.Client().Transport}}
+	for _, u := range []string{srv.URL, srv.URL + "/chunked"} {
+		for range 2 {
+			if body, status := get(t, c, u); body != big || status != "" {
+				t.Fatalf("%s: body=%q status=%q", u, body, status)
+			}
+		}
+	}
+	if hits != 4 {
+		t.Fatalf("hits=%d", hits)
+	}
+}
+
+func TestTransport_ServesStaleOnNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("cached"))
+	}))
+	c := &http.Client{Transport: &Transport{Dir: t.TempDir(), Base: srv.Client().Transport}}
+	get(t, c, srv.URL)
+	url := srv.URL
+	srv.Close()
+
+	if body, status := get(t, c, url); body != "cached" || status != "stale" {
+		t.Fatalf("body=%q status=%q", body, status)
+	}
+}
+
+func TestTransport_HonorsVary(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.URL.Path == "/any" {
+			w.Header().Set("Vary", "*")
+		} else {
+			w.Header().Set("Vary", "Accept")
+		}
+		_, _ = w.Write([]byte("as " + r.Header.Get("Accept")))
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &Transport{Dir: t.TempDir(), Base: srv.Client().Transport}}
+	getAccept := func(url, accept string) (string, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Accept", accept)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b), resp.Header.Get(StatusHeader)
+	}
+
+	getAccept(srv.URL, "text/plain")
+	if body, status := getAccept(srv.URL, "application/json"); body != "as application/json" || status != "" {
+		t.Fatalf("other Accept served from cache: body=%q status=%q", body, status)
+	}
+	if body, status := getAccept(srv.URL, "application/json"); body != "as application/json" || status != "hit" {
+		t.Fatalf("same Accept: body=%q status=%q", body, status)
+	}
+	// Alternating callers each keep their own entry.
+	if body, status := getAccept(srv.URL, "text/plain"); body != "as text/plain" || status != "hit" {
+		t.Fatalf("first Accept again: body=%q status=%q", body, status)
+	}
+
+	hits = 0
+	getAccept(srv.URL+"/any", "text/plain")
+	getAccept(srv.URL+"/any", "text/plain")
+	if hits != 2 {
+		t.Fatalf("Vary: * must not be cached, hits=%d", hits)
+	}
+}
+
+func TestTransport_PrunesOldAndOversizedEntries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.json")
+	if err := os.WriteFile(old, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &Transport{Dir: dir, MaxAge: time.Hour, Base: srv.Client().Transport}
+	c := &http.Client{Transport: tr}
+	get(t, c, srv.URL+"/a")
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("expected old entry to be pruned, stat err=%v", err)
+	}
+	entries, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries=%v err=%v", entries, err)
+	}
+	first := entries[0]
+	info, err := os.Stat(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Room for two entries.
+	tr.MaxSize = 2*info.Size() + info.Size()/2
+	for _, p := range []string{"/b", "/c"} {
+		// Distinct modification times, so /a is the oldest.
+		time.Sleep(10 * time.Millisecond)
+		get(t, c, srv.URL+p)
+	}
+
+	entries, err = filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || slices.Contains(entries, first) {
+		t.Fatalf("expected the 2 newest entries left, got %v", entries)
+	}
+}
@@ -0,0 +1,269 @@
+package install
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Limits for archive sources, to keep a hostile or broken archive from
+// filling the disk.
+const (
+	maxArchiveBytes   = 100 << 20 // download / file size
+	maxExtractedBytes = 500 << 20 // total uncompressed size
+	maxArchiveFiles   = 20000
+)
+
+// archiveHTTPClient downloads archive URLs; replaced in tests.
+var archiveHTTPClient = &http.Client{Timeout: 5 * time.Minute}
+
+// IsArchiveSource reports whether source names a .tar.gz, .tgz or .zip
+// archive, by URL or local path, rather than a git repository.
+func IsArchiveSource(source string) bool {
+	_, ok := archiveKind(source)
+	return ok
+}
+
+func archiveKind(source string) (string, bool) {
+	s := strings.TrimSpace(source)
+	if u, err := url.Parse(s); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		s = u.Path
+	}
+	s = strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(s, ".tar.gz"), strings.HasSuffix(s, ".tgz"):
+		return "tar.gz", true
+	case strings.HasSuffix(s, ".zip"):
+		return "zip", true
+	}
+	return "", false
+}
+
+// archiveLocation returns the URL or absolute path recorded for an archive
+// source, which is also what the policy checks.
+func archiveLocation(source string) (string, error) {
+	s := strings.TrimSpace(source)
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return s, nil
+	}
+	return filepath.Abs(expandHome(strings.TrimPrefix(s, "file://")))
+}
+
+// fetchArchive downloads or copies the archive at location, checks it
+// against wantSHA256 when given, and extracts it into destDir. A single
+// top-level directory, as in most release tarballs, becomes the root. It
+// returns the archive's SHA-256.
+func fetchArchive(ctx context.Context, location string, wantSHA256 string, destDir string) (string, error) {
+	kind, _ := archiveKind(location)
+
+	tmp, err := os.CreateTemp("", "skulls-archive-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	src, err := openArchive(ctx, location)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(src, maxArchiveBytes+1))
+	_ = src.Close()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		return "", fmt.Errorf("read archive: %w", err)
+	}
+	if n > maxArchiveBytes {
+		return "", fmt.Errorf("archive is larger than %d MiB", maxArchiveBytes>>20)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	if want := strings.ToLower(strings.TrimSpace(wantSHA256)); want != "" && want != sum {
+		return "", fmt.Errorf("archive checksum mismatch: expected sha256 %s, got %s", want, sum)
+	}
+
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return "", err
+	}
+	if kind == "zip" {
+		err = extractZip(tmp, n, destDir)
+	} else {
+		if _, err = tmp.Seek(0, io.SeekStart); err == nil {
+			err = extractTarGz(tmp, destDir)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("extract archive: %w", err)
+	}
+	return sum, hoistSingleDir(destDir)
+}
+
+func openArchive(ctx context.Context, location string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.Open(location)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := archiveHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("download %s: %s", location, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// extractor enforces the size and file count limits and keeps every entry
+// inside destDir.
+type extractor struct {
+	destDir string
+	files   int
+	written int64
+}
+
+// target returns where the archive entry name goes, rejecting absolute
+// paths and anything escaping destDir (zip-slip).
+func (x *extractor) target(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, "\\", "/")
+	clean := path.Clean(slashed)
+	if path.IsAbs(slashed) || filepath.VolumeName(name) != "" || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("unsafe path in archive: %q", name)
+	}
+	return filepath.Join(x.destDir, filepath.FromSlash(clean)), nil
+}
+
+func (x *extractor) writeFile(name string, r io.Reader, mode os.FileMode) error {
+	dest, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	if x.files++; x.files > maxArchiveFiles {
+		return fmt.Errorf("archive has more than %d files", maxArchiveFiles)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0o600)
+	if err != nil {
+		return err
+	}
+	remaining := maxExtractedBytes - x.written
+	n, err := io.Copy(f, io.LimitReader(r, remaining+1))
+	x.written += n
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if n > remaining {
+		return fmt.Errorf("archive expands to more than %d MiB", maxExtractedBytes>>20)
+	}
+	return nil
+}
+
+func (x *extractor) mkdir(name string) error {
+	dest, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(dest, 0o755)
+}
+
+// extractTarGz extracts regular files and directories. Links and special
+// files are skipped: a skill has no use for them and they can point outside
+// the tree.
+func extractTarGz(r io.Reader, destDir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = gz.Close() }()
+
+	x := &extractor{destDir: destDir}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(hdr.Name)
+		case tar.TypeReg:
+			err = x.writeFile(hdr.Name, tr, hdr.FileInfo().Mode())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip extracts regular files and directories, like extractTarGz.
+func extractZip(r io.ReaderAt, size int64, destDir string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	x := &extractor{destDir: destDir}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.mkdir(f.Name)
+		case mode.IsRegular():
+			var rc io.ReadCloser
+			if rc, err = f.Open(); err == nil {
+				err = x.writeFile(f.Name, rc, mode)
+				_ = rc.Close()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hoistSingleDir moves the contents of dir's only entry up into dir when
+// that entry is a directory.
+func hoistSingleDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return err
+	}
+	inner := filepath.Join(dir, entries[0].Name())
+	tmp := dir + ".hoist"
+	if err := os.Rename(inner, tmp); err != nil {
+		return err
+	}
+	if err := os.Remove(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
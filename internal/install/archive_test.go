@@ -0,0 +1,150 @@
+package install
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const archiveSkillMd = "---\nname: lint\ndescription: test\n---\n# Lint\n"
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInstallSkill_FromTarGzURL_RecordsChecksum(t *testing.T) {
+	archive := tarGz(t, map[string]string{"skills-1.0/skills/lint/SKILL.md": archiveSkillMd})
+	sum := sha256.Sum256(archive)
+	want := hex.EncodeToString(sum[:])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	}))
+	defer srv.Close()
+
+	target := t.TempDir()
+	source := srv.URL + "/releases/skills-1.0.tar.gz"
+	installed, err := InstallSkill(context.Background(), source, "lint", Options{TargetDir: target, SHA256: strings.ToUpper(want)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(filepath.Join(installed, "SKILL.md")); err != nil || string(b) != archiveSkillMd {
+		t.Fatalf("SKILL.md=%q err=%v", b, err)
+	}
+
+	md, err := ReadMetadata(target, "lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.SHA256 != want || md.URL != source || md.Commit != "" || md.Path != "skills/lint" {
+		t.Fatalf("metadata=%+v", md)
+	}
+}
+
+func TestInstallSkill_FromLocalZip(t *testing.T) {
+	tmp := t.TempDir()
+	archive := filepath.Join(tmp, "skills.zip")
+	if err := os.WriteFile(archive, zipBytes(t, map[string]string{"skills/lint/SKILL.md": archiveSkillMd, "README.md": "hi"}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	skills, cleanup, err := DiscoverSkills(context.Background(), archive, DiscoverOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup()
+	if len(skills) != 1 || skills[0].Name != "lint" {
+		t.Fatalf("skills=%+v", skills)
+	}
+
+	if _, err := InstallSkill(context.Background(), archive, "lint", Options{TargetDir: filepath.Join(tmp, "target")}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstallSkill_ArchiveChecksumMismatch(t *testing.T) {
+	tmp := t.TempDir()
+	archive := filepath.Join(tmp, "skills.tgz")
+	if err := os.WriteFile(archive, tarGz(t, map[string]string{"skills/lint/SKILL.md": archiveSkillMd}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := InstallSkill(context.Background(), archive, "lint", Options{TargetDir: filepath.Join(tmp, "target"), SHA256: strings.Repeat("0", 64)})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestInstallSkill_ArchiveRejectsPathTraversal(t *testing.T) {
+	tmp := t.TempDir()
+	for name, data := range map[string][]byte{
+		"slip.tar.gz": tarGz(t, map[string]string{"skills/lint/SKILL.md": archiveSkillMd, "../../evil": "x"}),
+		"slip.zip":    zipBytes(t, map[string]string{"skills/lint/SKILL.md": archiveSkillMd, "../evil": "x"}),
+	} {
+		archive := filepath.Join(tmp, name)
+		if err := os.WriteFile(archive, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := InstallSkill(context.Background(), archive, "lint", Options{TargetDir: filepath.Join(tmp, "target")})
+		if err == nil || !strings.Contains(err.Error(), "unsafe path") {
+			t.Fatalf("%s: expected unsafe path error, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(tmp), "evil")); err == nil {
+		t.Fatalf("archive wrote outside the extraction dir")
+	}
+}
+
+func TestInstallSkill_ArchiveRejectsRef(t *testing.T) {
+	_, err := InstallSkill(context.Background(), "https://example.com/skills.zip", "lint", Options{TargetDir: t.TempDir(), Ref: "v1"})
+	if err == nil || !strings.Contains(err.Error(), "--ref") {
+		t.Fatalf("expected --ref error, got %v", err)
+	}
+}
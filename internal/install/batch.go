@@ -0,0 +1,217 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+)
+
+// DefaultJobs is how many sources InstallBatch clones at once by default.
+const DefaultJobs = 4
+
+// BatchItem is one skill to install with InstallBatch.
+type BatchItem struct {
+	Source  string
+	SkillID string
+	// Ref and Path are as in Options, for this item only.
+	Ref  string
+	Path string
+}
+
+// String names it as source@skill-id, for progress and result lines.
+func (it BatchItem) String() string {
+	return strings.TrimSpace(it.Source) + "@" + strings.TrimSpace(it.SkillID)
+}
+
+// BatchResult is the outcome of one BatchItem.
+type BatchResult struct {
+	Item          BatchItem
+	InstalledPath string
+	Err           error
+}
+
+// BatchOptions configures InstallBatch. Options.Ref, Options.Path and
+// Options.Progress are ignored in favor of the per-item fields and Progress.
+type BatchOptions struct {
+	Options
+
+	// Jobs bounds how many sources are cloned concurrently; <= 0 means
+	// DefaultJobs.
+	Jobs int
+
+	// Progress, if set, receives the events of items[item]. It's called
+	// from several goroutines at once.
+	Progress func(item int, e Event)
+}
+
+// InstallBatch installs items concurrently and returns one result per item,
+// in order. Items sharing a source and ref are installed from a single
+// clone; installs into the same target folder never overlap.
+func InstallBatch(ctx context.Context, items []BatchItem, opts BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(items))
+	for i, it := range items {
+		results[i].Item = it
+	}
+
+	targetBase, err := filepath.Abs(expandHome(opts.TargetDir))
+	if err == nil {
+		err = os.MkdirAll(targetBase, 0o755)
+	}
+	if err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = DefaultJobs
+	}
+	b := &batch{opts: opts, targetBase: targetBase, results: results, folders: map[string]*sync.Mutex{}}
+	groups := groupBySource(items)
+
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for _, g := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				b.installGroup(ctx, items, g)
+			case <-ctx.Done():
+				for _, i := range g {
+					results[i].Err = ctx.Err()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+type batch struct {
+	opts       BatchOptions
+	targetBase string
+	results    []BatchResult
+
+	mu      sync.Mutex
+	folders map[string]*sync.Mutex
+}
+
+// groupBySource returns item indexes grouped by normalized source and ref,
+// in order of first appearance.
+func groupBySource(items []BatchItem) [][]int {
+	var groups [][]int
+	byKey := map[string]int{}
+	for i, it := range items {
+		key := sourceKey(it.Source) + "#" + strings.TrimSpace(it.Ref)
+		g, ok := byKey[key]
+		if !ok {
+			g = len(groups)
+			byKey[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// sourceKey identifies the repository source resolves to, so that
+// "owner/repo" and "https://github.com/owner/repo" share a clone.
+func sourceKey(source string) string {
+	if IsArchiveSource(source) {
+		if loc, err := archiveLocation(source); err == nil {
+			return loc
+		}
+		return strings.TrimSpace(source)
+	}
+	u, err := gitutil.NormalizeSourceToGitURL(source)
+	if err != nil {
+		return strings.TrimSpace(source)
+	}
+	if strings.Contains(u, "://") || strings.HasPrefix(u, "git@") {
+		u = strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git"))
+	}
+	return u
+}
+
+// installGroup clones the group's source once and installs each of its items
+// from that clone.
+func (b *batch) installGroup(ctx context.Context, items []BatchItem, group []int) {
+	first := items[group[0]]
+	opts := b.opts.Options
+	opts.Ref = first.Ref
+	opts.Path = ""
+	if len(group) == 1 {
+		opts.Path = first.Path
+	}
+	if b.opts.Progress != nil {
+		// Clone events belong to every item of the group.
+		opts.Progress = func(e Event) {
+			for _, i := range group {
+				b.opts.Progress(i, e)
+			}
+		}
+	}
+
+	fail := func(err error) {
+		for _, i := range group {
+			b.results[i].Err = err
+		}
+	}
+	tmp, err := os.MkdirTemp("", "skulls-*")
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	// A path only applies to a group of one, so it's that item's skill.
+	repo, err := fetchRepo(ctx, first.Source, strings.TrimSpace(first.SkillID), opts, filepath.Join(tmp, "repo"))
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	for _, i := range group {
+		it := items[i]
+		itemOpts := opts
+		if b.opts.Progress != nil {
+			itemOpts.Progress = func(e Event) { b.opts.Progress(i, e) }
+		}
+		repo := repo
+		repo.md.Source = strings.TrimSpace(it.Source)
+		b.results[i].InstalledPath, b.results[i].Err = b.place(ctx, repo, it.SkillID, itemOpts)
+	}
+}
+
+// place is placeSkill, holding the lock of the item's target folder.
+func (b *batch) place(ctx context.Context, repo fetchedRepo, skillID string, opts Options) (string, error) {
+	skillID = strings.TrimSpace(skillID)
+	if skillID == "" {
+		return "", errors.New("skill-id is required")
+	}
+	lock := b.folderLock(sanitizeName(skillID))
+	lock.Lock()
+	defer lock.Unlock()
+	return placeSkill(ctx, repo, skillID, b.targetBase, opts)
+}
+
+func (b *batch) folderLock(folder string) *sync.Mutex {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	l, ok := b.folders[folder]
+	if !ok {
+		l = &sync.Mutex{}
+		b.folders[folder] = l
+	}
+	return l
+}
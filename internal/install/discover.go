@@ -1,6 +1,7 @@
 package install
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -8,16 +9,48 @@ import (
 	"sort"
 
 	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/policy"
 	"github.com/kaofelix/skulls/internal/skilllayout"
 )
 
 type DiscoveredSkill struct {
 	Name          string
+	Description   string
+	Tags          []string
 	SkillDirPath  string
 	SkillFilePath string
+
+	// Path is the skill dir relative to the repository root, slash-separated.
+	Path string
+}
+
+// DiscoverOptions configures DiscoverSkills.
+type DiscoverOptions struct {
+	// Ref is a branch, tag or commit to discover skills at. Local
+	// directories are cloned instead of read in place when it's set.
+	Ref string
+
+	// Tokens authenticate HTTPS clones of private repositories.
+	Tokens []gitutil.Token
+
+	// SHA256, if set, must match the archive for archive sources.
+	SHA256 string
+
+	// Policy, if set, must allow the normalized source before anything is cloned.
+	Policy policy.Set
+
+	// Git clones git sources; nil means gitutil.DefaultBackend().
+	Git gitutil.Backend
 }
 
-type discoverOptions struct {
+func (o DiscoverOptions) git() gitutil.Backend {
+	if o.Git != nil {
+		return o.Git
+	}
+	return gitutil.DefaultBackend()
+}
+
+type scanOptions struct {
 	FullDepth bool
 }
 
@@ -26,29 +59,56 @@ type discoverOptions struct {
 //   - root SKILL.md (early return by default)
 //   - priority skill directories
 //   - recursive fallback
-func DiscoverSkills(source string) ([]DiscoveredSkill, func(), error) {
+//
+// Local directories are read in place unless a Ref is given.
+func DiscoverSkills(ctx context.Context, source string, opts DiscoverOptions) ([]DiscoveredSkill, func(), error) {
+	if IsArchiveSource(source) {
+		return discoverArchive(ctx, source, opts)
+	}
 	cloneURL, err := gitutil.NormalizeSourceToGitURL(source)
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := opts.Policy.CheckURL(cloneURL); err != nil {
+		return nil, nil, err
+	}
 
 	repoDir := cloneURL
 	cleanup := func() {}
 
-	if fi, err := os.Stat(cloneURL); err != nil || !fi.IsDir() {
+	if fi, err := os.Stat(cloneURL); err != nil || !fi.IsDir() || opts.Ref != "" {
 		tmp, err := os.MkdirTemp("", "skulls-discover-*")
 		if err != nil {
 			return nil, nil, err
 		}
 		repoDir = filepath.Join(tmp, "repo")
-		if err := gitutil.CloneShallowTo(cloneURL, repoDir, io.Discard, io.Discard); err != nil {
+		if err := opts.git().Clone(ctx, cloneURL, repoDir, gitutil.CloneOptions{Ref: opts.Ref, Tokens: opts.Tokens, Stdout: io.Discard, Stderr: io.Discard}); err != nil {
 			_ = os.RemoveAll(tmp)
 			return nil, nil, err
 		}
 		cleanup = func() { _ = os.RemoveAll(tmp) }
 	}
 
-	skills, err := discoverSkillsInRepo(repoDir, discoverOptions{})
+	skills, err := discoverSkillsInRepo(repoDir, scanOptions{})
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return skills, cleanup, nil
+}
+
+func discoverArchive(ctx context.Context, source string, opts DiscoverOptions) ([]DiscoveredSkill, func(), error) {
+	tmp, err := os.MkdirTemp("", "skulls-discover-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(tmp) }
+	repoDir := filepath.Join(tmp, "repo")
+	if _, err := extractSource(ctx, source, Options{Policy: opts.Policy, SHA256: opts.SHA256, Ref: opts.Ref}, repoDir); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	skills, err := discoverSkillsInRepo(repoDir, scanOptions{})
 	if err != nil {
 		cleanup()
 		return nil, nil, err
@@ -56,7 +116,7 @@ func DiscoverSkills(source string) ([]DiscoveredSkill, func(), error) {
 	return skills, cleanup, nil
 }
 
-func discoverSkillsInRepo(repoDir string, opts discoverOptions) ([]DiscoveredSkill, error) {
+func discoverSkillsInRepo(repoDir string, opts scanOptions) ([]DiscoveredSkill, error) {
 	out := make([]DiscoveredSkill, 0, 16)
 	seen := map[string]struct{}{}
 
@@ -75,8 +135,11 @@ func discoverSkillsInRepo(repoDir string, opts discoverOptions) ([]DiscoveredSki
 		seen[fm.Name] = struct{}{}
 		out = append(out, DiscoveredSkill{
 			Name:          fm.Name,
+			Description:   fm.Description,
+			Tags:          fm.Tags,
 			SkillDirPath:  filepath.Dir(skillFilePath),
 			SkillFilePath: skillFilePath,
+			Path:          filepath.ToSlash(relSkillPath(repoDir, filepath.Dir(skillFilePath))),
 		})
 		return nil
 	}
@@ -23,7 +23,7 @@ func TestDiscoverSkills_RootSkillEarlyReturnByDefault(t *testing.T) {
 	mustWrite("SKILL.md", "---\nname: root-skill\ndescription: root\n---\n")
 	mustWrite("skills/nested/SKILL.md", "---\nname: nested-skill\ndescription: nested\n---\n")
 
-	skills, err := discoverSkillsInRepo(repo, discoverOptions{})
+	skills, err := discoverSkillsInRepo(repo, scanOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -51,7 +51,7 @@ func TestDiscoverSkills_FullDepthIncludesRootAndNested(t *testing.T) {
 	mustWrite("SKILL.md", "---\nname: root-skill\ndescription: root\n---\n")
 	mustWrite("skills/nested/SKILL.md", "---\nname: nested-skill\ndescription: nested\n---\n")
 
-	skills, err := discoverSkillsInRepo(repo, discoverOptions{FullDepth: true})
+	skills, err := discoverSkillsInRepo(repo, scanOptions{FullDepth: true})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -80,7 +80,7 @@ func TestDiscoverSkills_FindsPriorityAgentDirs(t *testing.T) {
 
 	mustWrite(".claude/skills/alpha/SKILL.md", "---\nname: alpha\ndescription: a\n---\n")
 
-	skills, err := discoverSkillsInRepo(repo, discoverOptions{})
+	skills, err := discoverSkillsInRepo(repo, scanOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -104,7 +104,7 @@ func TestDiscoverSkills_RecursiveFallbackFindsNonStandardLayout(t *testing.T) {
 
 	mustWrite("custom/catalog/my-skill/SKILL.md", "---\nname: my-skill\ndescription: d\n---\n")
 
-	skills, err := discoverSkillsInRepo(repo, discoverOptions{})
+	skills, err := discoverSkillsInRepo(repo, scanOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -128,7 +128,7 @@ func TestDiscoverSkills_StrictFrontmatterRequiresDescription(t *testing.T) {
 
 	mustWrite("skills/one/SKILL.md", "---\nname: one\n---\n")
 
-	_, err := discoverSkillsInRepo(repo, discoverOptions{})
+	_, err := discoverSkillsInRepo(repo, scanOptions{})
 	if err == nil {
 		t.Fatalf("expected no skills found error")
 	}
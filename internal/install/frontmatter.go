@@ -9,6 +9,7 @@ import (
 type skillFrontmatter struct {
 	Name        string
 	Description string
+	Tags        []string
 }
 
 func parseSkillFrontmatter(md string) (skillFrontmatter, bool) {
@@ -44,5 +45,37 @@ func parseSkillFrontmatter(md string) (skillFrontmatter, bool) {
 		return skillFrontmatter{}, false
 	}
 
-	return skillFrontmatter{Name: strings.TrimSpace(name), Description: strings.TrimSpace(description)}, true
+	return skillFrontmatter{
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+		Tags:        frontmatterTags(fm),
+	}, true
+}
+
+// frontmatterTags reads optional tags, either top-level or under metadata, as
+// a YAML list or a comma-separated string.
+func frontmatterTags(fm map[string]any) []string {
+	raw, ok := fm["tags"]
+	if !ok {
+		if meta, isMap := fm["metadata"].(map[string]any); isMap {
+			raw = meta["tags"]
+		}
+	}
+
+	var tags []string
+	switch v := raw.(type) {
+	case string:
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if t, ok := item.(string); ok && strings.TrimSpace(t) != "" {
+				tags = append(tags, strings.TrimSpace(t))
+			}
+		}
+	}
+	return tags
 }
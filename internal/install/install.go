@@ -1,15 +1,19 @@
 package install
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/kaofelix/skulls/internal/fsutil"
 	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/policy"
 )
 
 type Step string
@@ -17,6 +21,7 @@ type Step string
 const (
 	StepNormalize Step = "normalize"
 	StepClone     Step = "clone"
+	StepSignature Step = "signature"
 	StepVerify    Step = "verify"
 	StepRemove    Step = "remove"
 	StepCopy      Step = "copy"
@@ -26,6 +31,10 @@ type Event struct {
 	Step    Step
 	Message string
 	Done    bool
+
+	// Progress, if set, is git's transfer progress during StepClone. Message
+	// repeats the step's current message.
+	Progress *gitutil.Progress
 }
 
 type ProgressFunc func(Event)
@@ -34,6 +43,33 @@ type Options struct {
 	TargetDir string
 	Force     bool
 
+	// Ref pins a branch, tag or commit SHA. Empty means the default branch.
+	Ref string
+
+	// Path is the skill directory relative to the repository root, when
+	// already known (from an index, install metadata or --path). Git sources
+	// then fetch only that directory, falling back to a full shallow clone
+	// if the skill isn't there (git errors are returned as is).
+	Path string
+
+	// RequireSigned aborts the install unless the checked-out commit (or Ref,
+	// when it names an annotated tag) has a signature trusted by Verify.
+	RequireSigned bool
+	Verify        gitutil.VerifyOptions
+
+	// Tokens authenticate HTTPS clones of private repositories.
+	Tokens []gitutil.Token
+
+	// Git clones git sources; nil means gitutil.DefaultBackend().
+	Git gitutil.Backend
+
+	// SHA256, if set, must match the archive for archive sources (see
+	// IsArchiveSource).
+	SHA256 string
+
+	// Policy, if set, must allow the normalized source before anything is cloned.
+	Policy policy.Set
+
 	// Progress, if set, is called as the installer advances.
 	Progress ProgressFunc
 
@@ -43,7 +79,11 @@ type Options struct {
 	GitStderr io.Writer
 }
 
-func InstallSkill(source string, skillID string, opts Options) (string, error) {
+// InstallSkill installs skillID from source into opts.TargetDir. Files are
+// staged next to the target and moved into place at the end, so cancelling
+// ctx (or any failure) kills git, removes temp dirs and leaves an existing
+// install untouched.
+func InstallSkill(ctx context.Context, source string, skillID string, opts Options) (string, error) {
 	skillID = strings.TrimSpace(skillID)
 	if skillID == "" {
 		return "", errors.New("skill-id is required")
@@ -64,38 +104,72 @@ func InstallSkill(source string, skillID string, opts Options) (string, error) {
 	}
 	defer func() { _ = os.RemoveAll(tmp) }()
 
-	repoDir := filepath.Join(tmp, "repo")
-
-	if opts.Progress != nil {
-		opts.Progress(Event{Step: StepNormalize, Message: "Normalizing source"})
-	}
-	cloneURL, err := gitutil.NormalizeSourceToGitURL(source)
+	repo, err := fetchRepo(ctx, source, skillID, opts, filepath.Join(tmp, "repo"))
 	if err != nil {
 		return "", err
 	}
-	if opts.Progress != nil {
-		opts.Progress(Event{Step: StepNormalize, Message: "Source normalized", Done: true})
-	}
+	return placeSkill(ctx, repo, skillID, targetBase, opts)
+}
 
-	stdout := opts.GitStdout
-	stderr := opts.GitStderr
-	if stdout == nil {
-		stdout = os.Stdout
+// fetchedRepo is a cloned (or extracted, or copied) source, verified and
+// ready to install skills from.
+type fetchedRepo struct {
+	dir string
+	// md holds the provenance shared by every skill installed from dir.
+	md Metadata
+}
+
+// fetchRepo clones source into repoDir and, if required, verifies its
+// signature. skillID, if known, is the skill opts.Path should hold.
+func fetchRepo(ctx context.Context, source string, skillID string, opts Options, repoDir string) (fetchedRepo, error) {
+	if opts.RequireSigned && IsArchiveSource(source) {
+		return fetchedRepo{}, errors.New("archive sources can't be signature-verified; use --sha256 instead")
 	}
-	if stderr == nil {
-		stderr = os.Stderr
+	// Signatures are verified with `git verify-commit`/`verify-tag`.
+	if opts.RequireSigned && opts.git().Name() == gitutil.BackendBuiltin {
+		return fetchedRepo{}, errors.New("signature verification needs the git binary; install git or set git_backend to \"git\"")
 	}
 
-	if opts.Progress != nil {
-		opts.Progress(Event{Step: StepClone, Message: "Cloning repository"})
+	fetched, err := cloneSource(ctx, source, skillID, opts, repoDir)
+	if err != nil {
+		return fetchedRepo{}, err
 	}
-	if err := gitutil.CloneShallowTo(cloneURL, repoDir, stdout, stderr); err != nil {
-		return "", err
+	if opts.RequireSigned && fetched.Kind == KindLocal {
+		return fetchedRepo{}, fmt.Errorf("%s isn't a git repository, so it can't be signature-verified", fetched.URL)
 	}
-	if opts.Progress != nil {
-		opts.Progress(Event{Step: StepClone, Message: "Cloned: " + cloneURL, Done: true})
+
+	md := Metadata{Source: strings.TrimSpace(source), URL: fetched.URL, Ref: strings.TrimSpace(opts.Ref), Kind: fetched.Kind, SHA256: fetched.SHA256}
+	if commit, err := opts.git().HeadCommit(repoDir); err == nil && fetched.Kind == "" {
+		md.Commit = commit
 	}
 
+	if opts.RequireSigned {
+		if opts.Progress != nil {
+			opts.Progress(Event{Step: StepSignature, Message: "Verifying signature"})
+		}
+		verify := opts.Verify
+		verify.AllowedSignersFile = expandHome(verify.AllowedSignersFile)
+		verify.GPGHome = expandHome(verify.GPGHome)
+		sig, err := gitutil.VerifyCheckout(repoDir, opts.Ref, verify)
+		if err != nil {
+			return fetchedRepo{}, err
+		}
+		md.Signer = sig.Signer
+		md.SignerKey = sig.Key
+		if opts.Progress != nil {
+			opts.Progress(Event{Step: StepSignature, Message: "Signed by " + sig.Signer + " (" + sig.Object + ")", Done: true})
+		}
+	}
+	return fetchedRepo{dir: repoDir, md: md}, nil
+}
+
+// placeSkill copies skillID from repo into targetBase, replacing an existing
+// install only once the new files are complete.
+func placeSkill(ctx context.Context, repo fetchedRepo, skillID string, targetBase string, opts Options) (string, error) {
+	repoDir := repo.dir
+	md := repo.md
+	md.SkillID = skillID
+
 	if opts.Progress != nil {
 		opts.Progress(Event{Step: StepVerify, Message: "Resolving skill layout"})
 	}
@@ -106,35 +180,64 @@ func InstallSkill(source string, skillID string, opts Options) (string, error) {
 	}
 
 	if opts.Progress != nil {
-		relSkillDir, relErr := filepath.Rel(repoDir, skillDir)
-		if relErr != nil {
-			relSkillDir = skillDir
-		}
-		opts.Progress(Event{Step: StepVerify, Message: "Skill path: " + relSkillDir, Done: true})
+		opts.Progress(Event{Step: StepVerify, Message: "Skill path: " + relSkillPath(repoDir, skillDir), Done: true})
 	}
+	md.Path = filepath.ToSlash(relSkillPath(repoDir, skillDir))
 
 	folderName := sanitizeName(skillID)
 	installPath := filepath.Join(targetBase, folderName)
 
-	if _, statErr := os.Stat(installPath); statErr == nil {
-		if !opts.Force {
-			return "", fmt.Errorf("target already exists: %s (use --force to overwrite)", installPath)
-		}
+	_, statErr := os.Stat(installPath)
+	exists := statErr == nil
+	if exists && !opts.Force {
+		return "", fmt.Errorf("target already exists: %s (use --force to overwrite)", installPath)
+	}
+
+	// Stage inside the target dir so the final move is a rename on the same
+	// filesystem. ListInstalled ignores dot-dirs.
+	if opts.Progress != nil {
+		opts.Progress(Event{Step: StepCopy, Message: "Installing files to " + installPath})
+	}
+	staging, err := os.MkdirTemp(targetBase, ".skulls-staging-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+	staged := filepath.Join(staging, folderName)
+	if err := fsutil.CopyDir(skillDir, staged); err != nil {
+		return "", err
+	}
+	digest, err := DigestDir(staged)
+	if err != nil {
+		return "", err
+	}
+	md.Digest = digest.Sum
+	md.Files = digest.Files
+
+	// Last chance to back out before the target dir changes.
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if exists {
 		if opts.Progress != nil {
 			opts.Progress(Event{Step: StepRemove, Message: "Removing existing install: " + installPath})
 		}
-		if err := os.RemoveAll(installPath); err != nil {
+		if err := os.Rename(installPath, filepath.Join(staging, ".previous")); err != nil {
 			return "", err
 		}
 		if opts.Progress != nil {
 			opts.Progress(Event{Step: StepRemove, Message: "Removed existing install", Done: true})
 		}
 	}
-
-	if opts.Progress != nil {
-		opts.Progress(Event{Step: StepCopy, Message: "Installing files to " + installPath})
+	if err := os.Rename(staged, installPath); err != nil {
+		if exists {
+			_ = os.Rename(filepath.Join(staging, ".previous"), installPath)
+		}
+		return "", err
 	}
-	if err := fsutil.CopyDir(skillDir, installPath); err != nil {
+	md.InstalledAt = time.Now().UTC()
+	if err := writeMetadata(targetBase, folderName, md); err != nil {
 		return "", err
 	}
 	if opts.Progress != nil {
@@ -144,6 +247,228 @@ func InstallSkill(source string, skillID string, opts Options) (string, error) {
 	return installPath, nil
 }
 
+// FetchSkill clones (or, for archives, extracts) source at opts.Ref into a temp dir and returns the
+// directory of skillID inside it, without installing anything. The caller
+// must call cleanup once done with the files.
+func FetchSkill(ctx context.Context, source string, skillID string, opts Options) (string, func(), error) {
+	tmp, err := os.MkdirTemp("", "skulls-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(tmp) }
+
+	repoDir := filepath.Join(tmp, "repo")
+	if _, err := cloneSource(ctx, source, strings.TrimSpace(skillID), opts, repoDir); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	skillDir, err := resolveSkillDir(repoDir, strings.TrimSpace(skillID))
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return skillDir, cleanup, nil
+}
+
+// fetchedSource is the provenance of a cloned, extracted or copied source.
+type fetchedSource struct {
+	// URL is the clone URL, the archive URL or path, or the local directory.
+	URL string
+	// Kind is KindArchive or KindLocal; empty for git.
+	Kind string
+	// SHA256 is set for archives.
+	SHA256 string
+}
+
+// cloneSource normalizes source, checks it against the policy and clones it
+// into repoDir, reporting progress. Archive sources are extracted instead,
+// and local directories that aren't git repositories are copied.
+func cloneSource(ctx context.Context, source string, skillID string, opts Options, repoDir string) (fetchedSource, error) {
+	if opts.Progress != nil {
+		opts.Progress(Event{Step: StepNormalize, Message: "Normalizing source"})
+	}
+	if IsArchiveSource(source) {
+		return extractSource(ctx, source, opts, repoDir)
+	}
+	cloneURL, err := gitutil.NormalizeSourceToGitURL(source)
+	if err != nil {
+		return fetchedSource{}, err
+	}
+	if err := opts.Policy.CheckURL(cloneURL); err != nil {
+		return fetchedSource{}, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(Event{Step: StepNormalize, Message: "Source normalized", Done: true})
+	}
+	if isPlainLocalDir(cloneURL) {
+		return copyLocalSource(ctx, cloneURL, opts, repoDir)
+	}
+
+	stdout := opts.GitStdout
+	stderr := opts.GitStderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	git := opts.git()
+	cloneOpts := gitutil.CloneOptions{Ref: opts.Ref, Tokens: opts.Tokens, Stdout: stdout, Stderr: stderr}
+	var cloneMsg string
+	if opts.Progress != nil {
+		cloneOpts.Progress = func(p gitutil.Progress) {
+			opts.Progress(Event{Step: StepClone, Message: cloneMsg, Progress: &p})
+		}
+	}
+	// The builtin backend can't fetch a subset of blobs.
+	if p, ok := sparsePath(opts.Path); ok && git.Name() != gitutil.BackendBuiltin {
+		cloneMsg = "Cloning " + p + " only (sparse checkout)"
+		if opts.Progress != nil {
+			opts.Progress(Event{Step: StepClone, Message: cloneMsg})
+		}
+		cloneOpts.SparsePaths = []string{p}
+		if err := git.Clone(ctx, cloneURL, repoDir, cloneOpts); err != nil {
+			return fetchedSource{}, err
+		}
+		// Only a missing (or different) skill at the path is worth a full
+		// clone; git errors would just happen again.
+		if hasSkillAt(filepath.Join(repoDir, filepath.FromSlash(p)), skillID) {
+			if opts.Progress != nil {
+				opts.Progress(Event{Step: StepClone, Message: "Cloned: " + cloneURL + " (sparse: " + p + ")", Done: true})
+			}
+			return fetchedSource{URL: cloneURL}, nil
+		}
+		if err := os.RemoveAll(repoDir); err != nil {
+			return fetchedSource{}, err
+		}
+		cloneOpts.SparsePaths = nil
+		cloneMsg = "No skill at " + p + "; cloning repository (full)"
+		if opts.Progress != nil {
+			opts.Progress(Event{Step: StepClone, Message: cloneMsg})
+		}
+	} else {
+		cloneMsg = "Cloning repository (full)"
+		if opts.Progress != nil {
+			opts.Progress(Event{Step: StepClone, Message: cloneMsg})
+		}
+	}
+	if err := git.Clone(ctx, cloneURL, repoDir, cloneOpts); err != nil {
+		return fetchedSource{}, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(Event{Step: StepClone, Message: "Cloned: " + cloneURL + " (full)", Done: true})
+	}
+	return fetchedSource{URL: cloneURL}, nil
+}
+
+func (o Options) git() gitutil.Backend {
+	if o.Git != nil {
+		return o.Git
+	}
+	return gitutil.DefaultBackend()
+}
+
+// sparsePath cleans a known skill path for a sparse checkout. The repository
+// root can't be checked out sparsely.
+func sparsePath(p string) (string, bool) {
+	p = strings.Trim(filepath.ToSlash(strings.TrimSpace(p)), "/")
+	if p == "" {
+		return "", false
+	}
+	p = path.Clean(p)
+	if p == "." || p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+	return p, true
+}
+
+// hasSkillAt reports whether dir holds a SKILL.md, named skillID when that's
+// known.
+func hasSkillAt(dir string, skillID string) bool {
+	b, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		return false
+	}
+	if skillID == "" {
+		return true
+	}
+	fm, ok := parseSkillFrontmatter(string(b))
+	return ok && fm.Name == skillID
+}
+
+// extractSource is cloneSource for archive sources.
+func extractSource(ctx context.Context, source string, opts Options, repoDir string) (fetchedSource, error) {
+	if strings.TrimSpace(opts.Ref) != "" {
+		return fetchedSource{}, errors.New("--ref can't be used with archive sources")
+	}
+	location, err := archiveLocation(source)
+	if err != nil {
+		return fetchedSource{}, err
+	}
+	if err := opts.Policy.CheckURL(location); err != nil {
+		return fetchedSource{}, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(Event{Step: StepNormalize, Message: "Source normalized", Done: true})
+		opts.Progress(Event{Step: StepClone, Message: "Downloading archive"})
+	}
+	sum, err := fetchArchive(ctx, location, opts.SHA256, repoDir)
+	if err != nil {
+		return fetchedSource{}, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(Event{Step: StepClone, Message: "Extracted: " + location + " (sha256 " + sum[:12] + ")", Done: true})
+	}
+	return fetchedSource{URL: location, Kind: KindArchive, SHA256: sum}, nil
+}
+
+// isPlainLocalDir reports whether p is a local directory without a .git, so
+// there is nothing to clone.
+func isPlainLocalDir(p string) bool {
+	if !filepath.IsAbs(p) {
+		return false
+	}
+	if fi, err := os.Stat(p); err != nil || !fi.IsDir() {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(p, ".git"))
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// copyLocalSource is cloneSource for plain local directories: the tree is
+// copied as is, minus what its .gitignore files exclude.
+func copyLocalSource(ctx context.Context, dir string, opts Options, repoDir string) (fetchedSource, error) {
+	if strings.TrimSpace(opts.Ref) != "" {
+		return fetchedSource{}, fmt.Errorf("--ref needs a git repository, and %s isn't one", dir)
+	}
+	ignore, err := fsutil.LoadGitignore(dir)
+	if err != nil {
+		return fetchedSource{}, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(Event{Step: StepClone, Message: "Copying local directory"})
+	}
+	if err := fsutil.CopyDirSkipping(dir, repoDir, ignore.Match); err != nil {
+		return fetchedSource{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return fetchedSource{}, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(Event{Step: StepClone, Message: "Copied: " + dir, Done: true})
+	}
+	return fetchedSource{URL: dir, Kind: KindLocal}, nil
+}
+
+func relSkillPath(repoDir string, skillDir string) string {
+	rel, err := filepath.Rel(repoDir, skillDir)
+	if err != nil {
+		return skillDir
+	}
+	return rel
+}
+
 func expandHome(p string) string {
 	if p == "" {
 		return p
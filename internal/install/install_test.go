@@ -1,10 +1,19 @@
 package install
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/policy"
 )
 
 func TestInstallSkill_FromLocalRepo(t *testing.T) {
@@ -32,7 +41,7 @@ func TestInstallSkill_FromLocalRepo(t *testing.T) {
 	run("git", "commit", "-m", "init")
 
 	target := filepath.Join(tmp, "target")
-	installed, err := InstallSkill(repo, "hello-skill", Options{TargetDir: target})
+	installed, err := InstallSkill(context.Background(), repo, "hello-skill", Options{TargetDir: target})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -72,7 +81,7 @@ func TestInstallSkill_FallbackByFrontmatterName(t *testing.T) {
 	run("git", "commit", "-m", "init")
 
 	target := filepath.Join(tmp, "target")
-	installed, err := InstallSkill(repo, "vercel-composition-patterns", Options{TargetDir: target})
+	installed, err := InstallSkill(context.Background(), repo, "vercel-composition-patterns", Options{TargetDir: target})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -114,7 +123,7 @@ func TestInstallSkill_FromPriorityAgentDir(t *testing.T) {
 	run("git", "commit", "-m", "init")
 
 	target := filepath.Join(tmp, "target")
-	installed, err := InstallSkill(repo, "agent-skill", Options{TargetDir: target})
+	installed, err := InstallSkill(context.Background(), repo, "agent-skill", Options{TargetDir: target})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -126,3 +135,572 @@ func TestInstallSkill_FromPriorityAgentDir(t *testing.T) {
 		t.Fatalf("expected extra.txt to exist: %v", err)
 	}
 }
+
+func TestInstallSkill_PolicyDeniesBeforeClone(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "target")
+	pol := policy.Set{{Deny: []policy.Rule{{Owner: "blocked"}}}}
+
+	_, err := InstallSkill(context.Background(), "blocked/repo", "some-skill", Options{TargetDir: target, Policy: pol})
+	if !errors.Is(err, policy.ErrDenied) {
+		t.Fatalf("expected policy denial, got %v", err)
+	}
+	entries, readErr := os.ReadDir(target)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected target to stay empty, got %d entries", len(entries))
+	}
+}
+
+// newSignedRepo creates a repo with one skill whose commit and v1 tag are
+// signed with a fresh SSH key, and returns the repo path and an
+// allowed-signers file trusting that key.
+func newSignedRepo(t *testing.T, sign bool) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	tmp := t.TempDir()
+	key := filepath.Join(tmp, "signing-key")
+	repo := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, "skills", "signed-skill"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "skills", "signed-skill", "SKILL.md"), []byte("---\nname: signed-skill\ndescription: test\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v failed: %v\n%s", args, err, string(out))
+		}
+	}
+
+	run(tmp, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test@example.com", "-f", key)
+	run(repo, "git", "init")
+	run(repo, "git", "config", "gpg.format", "ssh")
+	run(repo, "git", "config", "user.signingkey", key)
+	run(repo, "git", "add", ".")
+	if sign {
+		run(repo, "git", "commit", "-S", "-m", "init")
+		run(repo, "git", "tag", "-s", "v1", "-m", "v1")
+	} else {
+		run(repo, "git", "commit", "-m", "init")
+	}
+
+	pub, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed := filepath.Join(tmp, "allowed_signers")
+	if err := os.WriteFile(allowed, []byte("test@example.com namespaces=\"git\" "+string(pub)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return repo, allowed
+}
+
+func TestInstallSkill_RequireSigned_RecordsSigner(t *testing.T) {
+	repo, allowed := newSignedRepo(t, true)
+	target := filepath.Join(t.TempDir(), "target")
+
+	_, err := InstallSkill(context.Background(), repo, "signed-skill", Options{
+		TargetDir:     target,
+		RequireSigned: true,
+		Verify:        gitutil.VerifyOptions{AllowedSignersFile: allowed},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md, err := ReadMetadata(target, "signed-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Signer != "test@example.com" {
+		t.Fatalf("signer=%q", md.Signer)
+	}
+	if !strings.HasPrefix(md.SignerKey, "SHA256:") {
+		t.Fatalf("signer key=%q", md.SignerKey)
+	}
+	if md.Commit == "" {
+		t.Fatalf("expected commit to be recorded")
+	}
+}
+
+func TestInstallSkill_RequireSigned_VerifiesPinnedTag(t *testing.T) {
+	repo, allowed := newSignedRepo(t, true)
+	target := filepath.Join(t.TempDir(), "target")
+
+	var events []Event
+	_, err := InstallSkill(context.Background(), repo, "signed-skill", Options{
+		TargetDir:     target,
+		Ref:           "v1",
+		RequireSigned: true,
+		Verify:        gitutil.VerifyOptions{AllowedSignersFile: allowed},
+		Progress:      func(e Event) { events = append(events, e) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, e := range events {
+		if e.Step == StepSignature && e.Done && strings.Contains(e.Message, "tag v1") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected tag signature event, got %+v", events)
+	}
+	md, err := ReadMetadata(target, "signed-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Ref != "v1" || md.Signer != "test@example.com" {
+		t.Fatalf("metadata=%+v", md)
+	}
+}
+
+func TestInstallSkill_RequireSigned_RejectsUnsignedCommit(t *testing.T) {
+	repo, allowed := newSignedRepo(t, false)
+	target := filepath.Join(t.TempDir(), "target")
+
+	_, err := InstallSkill(context.Background(), repo, "signed-skill", Options{
+		TargetDir:     target,
+		RequireSigned: true,
+		Verify:        gitutil.VerifyOptions{AllowedSignersFile: allowed},
+	})
+	if !errors.Is(err, gitutil.ErrBadSignature) {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(target, "signed-skill")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected nothing to be installed, stat err=%v", statErr)
+	}
+}
+
+func TestInstallSkill_RequireSigned_RejectsBuiltinBackend(t *testing.T) {
+	repo, allowed := newSignedRepo(t, true)
+
+	_, err := InstallSkill(context.Background(), repo, "signed-skill", Options{
+		TargetDir:     filepath.Join(t.TempDir(), "target"),
+		RequireSigned: true,
+		Verify:        gitutil.VerifyOptions{AllowedSignersFile: allowed},
+		Git:           gitutil.Builtin,
+	})
+	if err == nil || !strings.Contains(err.Error(), "needs the git binary") {
+		t.Fatalf("expected builtin backend to be rejected, got %v", err)
+	}
+}
+
+func TestInstallSkill_RequireSigned_RejectsUntrustedKey(t *testing.T) {
+	repo, _ := newSignedRepo(t, true)
+	target := filepath.Join(t.TempDir(), "target")
+	empty := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := InstallSkill(context.Background(), repo, "signed-skill", Options{
+		TargetDir:     target,
+		RequireSigned: true,
+		Verify:        gitutil.VerifyOptions{AllowedSignersFile: empty},
+	})
+	if !errors.Is(err, gitutil.ErrBadSignature) {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+}
+
+func TestInstallSkill_FromPlainLocalDir(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "scratch")
+	files := map[string]string{
+		".gitignore":                  "*.log\nnode_modules/\n",
+		"skills/draft/SKILL.md":       "---\nname: draft\ndescription: test\n---\n",
+		"skills/draft/notes.log":      "junk",
+		"skills/draft/node_modules/x": "junk",
+		"skills/draft/scripts/run.sh": "echo hi",
+	}
+	for rel, body := range files {
+		p := filepath.Join(src, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	target := filepath.Join(tmp, "target")
+	installed, err := InstallSkill(context.Background(), src, "draft", Options{TargetDir: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(installed, "scripts", "run.sh")); err != nil {
+		t.Fatalf("expected scripts/run.sh: %v", err)
+	}
+	for _, ignored := range []string{"notes.log", "node_modules"} {
+		if _, err := os.Stat(filepath.Join(installed, ignored)); err == nil {
+			t.Fatalf("expected %s to be left out", ignored)
+		}
+	}
+
+	md, err := ReadMetadata(target, "draft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Kind != KindLocal || md.URL != src || md.Commit != "" {
+		t.Fatalf("metadata=%+v", md)
+	}
+
+	if _, err := InstallSkill(context.Background(), src, "draft", Options{TargetDir: target, Force: true, Ref: "main"}); err == nil {
+		t.Fatalf("expected --ref to be rejected for a plain directory")
+	}
+}
+
+func TestFetchSkill_SparseCheckoutOfKnownPath(t *testing.T) {
+	tmp := t.TempDir()
+	repo := filepath.Join(tmp, "repo")
+	for _, name := range []string{"alpha", "beta"} {
+		dir := filepath.Join(repo, "skills", name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: "+name+"\ndescription: test\n---\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-qm", "init"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	var messages []string
+	opts := Options{
+		Path:      "skills/alpha",
+		GitStdout: io.Discard,
+		GitStderr: io.Discard,
+		Progress:  func(e Event) { messages = append(messages, e.Message) },
+	}
+	skillDir, cleanup, err := FetchSkill(context.Background(), repo, "alpha", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	if _, err := os.Stat(filepath.Join(skillDir, "..", "beta")); err == nil {
+		t.Fatalf("expected skills/beta to be left out of the sparse checkout")
+	}
+	if got := strings.Join(messages, "\n"); !strings.Contains(got, "(sparse: skills/alpha)") {
+		t.Fatalf("expected sparse strategy in progress, got:\n%s", got)
+	}
+
+	// A stale path falls back to a full clone.
+	messages = nil
+	opts.Path = "skills/moved"
+	skillDir, cleanup2, err := FetchSkill(context.Background(), repo, "beta", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup2()
+	if filepath.Base(skillDir) != "beta" {
+		t.Fatalf("skillDir=%s", skillDir)
+	}
+	if got := strings.Join(messages, "\n"); !strings.Contains(got, "(full)") {
+		t.Fatalf("expected full clone fallback in progress, got:\n%s", got)
+	}
+
+	// So does a path holding a different skill.
+	opts.Path = "skills/alpha"
+	skillDir, cleanup3, err := FetchSkill(context.Background(), repo, "beta", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup3()
+	if filepath.Base(skillDir) != "beta" {
+		t.Fatalf("skillDir=%s", skillDir)
+	}
+
+	// Git errors are returned as is, without retrying a full clone.
+	messages = nil
+	opts.Ref = "no-such-ref"
+	if _, _, err := FetchSkill(context.Background(), repo, "alpha", opts); !errors.Is(err, gitutil.ErrUnknownRef) {
+		t.Fatalf("expected unknown ref error, got %v", err)
+	}
+	if got := strings.Join(messages, "\n"); strings.Contains(got, "(full)") {
+		t.Fatalf("expected no full clone after a git error, got:\n%s", got)
+	}
+}
+
+func TestInstallSkill_CancelledLeavesExistingInstall(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "scratch")
+	if err := os.MkdirAll(filepath.Join(src, "draft"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeSkill := func(body string) {
+		if err := os.WriteFile(filepath.Join(src, "draft", "SKILL.md"), []byte("---\nname: draft\ndescription: "+body+"\n---\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSkill("v1")
+	target := filepath.Join(tmp, "target")
+	installed, err := InstallSkill(context.Background(), src, "draft", Options{TargetDir: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(filepath.Join(installed, "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cancel once the files are being copied, the last step before the
+	// existing install is replaced.
+	writeSkill("v2")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := Options{TargetDir: target, Force: true, Progress: func(e Event) {
+		if e.Step == StepCopy {
+			cancel()
+		}
+	}}
+	if _, err := InstallSkill(ctx, src, "draft", opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if after, err := os.ReadFile(filepath.Join(installed, "SKILL.md")); err != nil || string(after) != string(before) {
+		t.Fatalf("existing install changed: %q err=%v", after, err)
+	}
+	entries, err := os.ReadDir(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".skulls-staging") {
+			t.Fatalf("staging dir left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestInstallSkill_CancelledBeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	target := filepath.Join(t.TempDir(), "target")
+	if _, err := InstallSkill(ctx, "owner/repo", "demo", Options{TargetDir: target, GitStdout: io.Discard, GitStderr: io.Discard}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if entries, _ := os.ReadDir(target); len(entries) != 0 {
+		t.Fatalf("expected target to stay empty, got %d entries", len(entries))
+	}
+}
+
+func TestInstallSkill_WithBuiltinGitBackend(t *testing.T) {
+	tmp := t.TempDir()
+	repo := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, "skills", "hello-skill"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "skills", "hello-skill", "SKILL.md"), []byte("---\nname: hello-skill\ndescription: test\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var commit string
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-qm", "init"}, {"rev-parse", "HEAD"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		commit = strings.TrimSpace(string(out))
+	}
+
+	// No git binary from here on.
+	t.Setenv("PATH", "")
+	target := filepath.Join(tmp, "target")
+	if _, err := InstallSkill(context.Background(), repo, "hello-skill", Options{TargetDir: target, Path: "skills/hello-skill", Git: gitutil.Builtin}); err != nil {
+		t.Fatal(err)
+	}
+	md, err := ReadMetadata(target, "hello-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Commit != commit || md.Path != "skills/hello-skill" {
+		t.Fatalf("metadata=%+v, want commit %s", md, commit)
+	}
+
+	skills, cleanup, err := DiscoverSkills(context.Background(), repo, DiscoverOptions{Git: gitutil.Builtin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup()
+	if len(skills) != 1 || skills[0].Name != "hello-skill" {
+		t.Fatalf("skills=%+v", skills)
+	}
+}
+
+func TestInstallBatch_InstallsEachItemInOrder(t *testing.T) {
+	tmp := t.TempDir()
+	writeSkill := func(dir string, name string) {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "SKILL.md"), []byte("---\nname: "+name+"\ndescription: test\n---\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := filepath.Join(tmp, "first")
+	second := filepath.Join(tmp, "second")
+	writeSkill(first, "alpha")
+	writeSkill(first, "beta")
+	writeSkill(second, "gamma")
+
+	items := []BatchItem{
+		{Source: first, SkillID: "alpha"},
+		{Source: second, SkillID: "gamma"},
+		{Source: first, SkillID: "missing"},
+		{Source: first, SkillID: "beta"},
+	}
+	target := filepath.Join(tmp, "target")
+	seen := make([]bool, len(items))
+	var mu sync.Mutex
+	results := InstallBatch(context.Background(), items, BatchOptions{
+		Options: Options{TargetDir: target},
+		Jobs:    2,
+		Progress: func(i int, e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[i] = true
+		},
+	})
+
+	if len(results) != len(items) {
+		t.Fatalf("got %d results", len(results))
+	}
+	for i, r := range results {
+		if r.Item != items[i] {
+			t.Fatalf("result %d is for %+v", i, r.Item)
+		}
+		if !seen[i] {
+			t.Fatalf("no progress for item %d", i)
+		}
+		if r.Item.SkillID == "missing" {
+			if r.Err == nil {
+				t.Fatal("expected an error for the missing skill")
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Fatalf("%s: %v", r.Item.SkillID, r.Err)
+		}
+		if _, err := os.Stat(filepath.Join(r.InstalledPath, "SKILL.md")); err != nil {
+			t.Fatalf("%s: %v", r.Item.SkillID, err)
+		}
+		md, err := ReadMetadata(target, r.Item.SkillID)
+		if err != nil || md.Source != r.Item.Source {
+			t.Fatalf("%s: metadata=%+v err=%v", r.Item.SkillID, md, err)
+		}
+	}
+}
+
+func TestGroupBySource_SharesClonesPerSourceAndRef(t *testing.T) {
+	items := []BatchItem{
+		{Source: "owner/repo", SkillID: "a"},
+		{Source: "https://github.com/owner/repo", SkillID: "b"},
+		{Source: "owner/repo", SkillID: "c", Ref: "v1"},
+		{Source: "other/repo", SkillID: "d"},
+		{Source: "owner/repo", SkillID: "e", Ref: "v1"},
+	}
+	got := groupBySource(items)
+	want := [][]int{{0, 1}, {2, 4}, {3}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("groups=%v, want %v", got, want)
+	}
+}
+
+func TestUpdateAvailable_WhenUpstreamMoves(t *testing.T) {
+	tmp := t.TempDir()
+	repo := filepath.Join(tmp, "repo")
+	writeFiles(t, repo, map[string]string{
+		"skills/demo/SKILL.md": "---\nname: demo\ndescription: v1\n---\n",
+	})
+	gitRun(t, repo, "init")
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-m", "v1")
+
+	target := filepath.Join(tmp, "target")
+	if _, err := InstallSkill(context.Background(), repo, "demo", Options{TargetDir: target}); err != nil {
+		t.Fatal(err)
+	}
+	md, err := ReadMetadata(target, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := UpdateAvailable(context.Background(), md, Options{}); err != nil || ok {
+		t.Fatalf("fresh install: update=%v err=%v", ok, err)
+	}
+
+	writeFiles(t, repo, map[string]string{
+		"skills/demo/SKILL.md": "---\nname: demo\ndescription: v2\n---\n",
+	})
+	gitRun(t, repo, "commit", "-am", "v2")
+	if ok, err := UpdateAvailable(context.Background(), md, Options{}); err != nil || !ok {
+		t.Fatalf("after upstream commit: update=%v err=%v", ok, err)
+	}
+
+	pinned := md
+	pinned.Ref = md.Commit
+	if ok, err := UpdateAvailable(context.Background(), pinned, Options{}); err != nil || ok {
+		t.Fatalf("pinned commit: update=%v err=%v", ok, err)
+	}
+}
+
+func TestUpdateAvailable_ChecksPolicyFirst(t *testing.T) {
+	md := Metadata{Source: "blocked/skills", URL: "https://github.com/blocked/skills.git", Ref: "main", Commit: "0123456789abcdef0123456789abcdef01234567"}
+	pol := policy.Set{{Deny: []policy.Rule{{Owner: "blocked"}}}}
+
+	if _, err := UpdateAvailable(context.Background(), md, Options{Policy: pol}); !errors.Is(err, policy.ErrDenied) {
+		t.Fatalf("expected policy denial, got %v", err)
+	}
+}
+
+func TestSameSource_ComparesNormalizedSources(t *testing.T) {
+	if !SameSource("owner/repo", "https://github.com/Owner/repo.git") {
+		t.Fatal("expected shorthand and URL to match")
+	}
+	if SameSource("owner/repo", "other/repo") {
+		t.Fatal("expected different repositories not to match")
+	}
+}
+
+func TestUninstall_RemovesFolderAndMetadata(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	writeFiles(t, src, map[string]string{"demo/SKILL.md": "---\nname: demo\ndescription: test\n---\n"})
+	target := filepath.Join(tmp, "target")
+	if _, err := InstallSkill(context.Background(), src, "demo", Options{TargetDir: target}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Uninstall(target, "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if skills, err := ListInstalled(target); err != nil || len(skills) != 0 {
+		t.Fatalf("skills=%+v err=%v", skills, err)
+	}
+	if orphans, err := OrphanedMetadata(target); err != nil || len(orphans) != 0 {
+		t.Fatalf("orphans=%v err=%v", orphans, err)
+	}
+	if err := Uninstall(target, "../target"); err == nil {
+		t.Fatal("expected an error for a folder outside the target dir")
+	}
+}
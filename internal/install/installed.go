@@ -0,0 +1,173 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+)
+
+// InstalledSkill is a skill folder found in a target dir.
+type InstalledSkill struct {
+	Folder string
+	Path   string
+
+	// Name and Description come from SKILL.md. Valid is false when SKILL.md
+	// is missing or its frontmatter is invalid.
+	Name        string
+	Description string
+	Valid       bool
+
+	// Metadata is nil for skills installed without metadata (e.g. by hand or
+	// by an older skulls).
+	Metadata *Metadata
+}
+
+// SkillID returns the recorded skill id, falling back to the SKILL.md name
+// and then the folder name.
+func (s InstalledSkill) SkillID() string {
+	if s.Metadata != nil && strings.TrimSpace(s.Metadata.SkillID) != "" {
+		return s.Metadata.SkillID
+	}
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Folder
+}
+
+// FolderName returns the folder a skill id is installed into.
+func FolderName(skillID string) string {
+	return sanitizeName(skillID)
+}
+
+// FolderPath returns the absolute path of folder inside targetDir.
+func FolderPath(targetDir string, folder string) (string, error) {
+	base, err := filepath.Abs(expandHome(targetDir))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, folder), nil
+}
+
+// ListInstalled returns the skill folders in targetDir sorted by folder name.
+// A missing target dir yields no skills.
+func ListInstalled(targetDir string) ([]InstalledSkill, error) {
+	base, err := filepath.Abs(expandHome(targetDir))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	out := make([]InstalledSkill, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		s := InstalledSkill{Folder: e.Name(), Path: filepath.Join(base, e.Name())}
+		if b, err := os.ReadFile(filepath.Join(s.Path, "SKILL.md")); err == nil {
+			if fm, ok := parseSkillFrontmatter(string(b)); ok {
+				s.Name = fm.Name
+				s.Description = fm.Description
+				s.Valid = true
+			}
+		}
+		if md, err := ReadMetadata(base, e.Name()); err == nil {
+			s.Metadata = &md
+		}
+		out = append(out, s)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Folder < out[j].Folder })
+	return out, nil
+}
+
+// Uninstall removes the skill folder and its install metadata from
+// targetDir.
+func Uninstall(targetDir string, folder string) error {
+	base, err := filepath.Abs(expandHome(targetDir))
+	if err != nil {
+		return err
+	}
+	if folder == "" || folder != filepath.Base(folder) || strings.HasPrefix(folder, ".") {
+		return fmt.Errorf("invalid skill folder %q", folder)
+	}
+	if err := os.RemoveAll(filepath.Join(base, folder)); err != nil {
+		return err
+	}
+	if err := os.Remove(metadataPath(base, folder)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SameSource reports whether sources a and b name the same repository or
+// archive, e.g. "owner/repo" and "https://github.com/owner/repo".
+func SameSource(a string, b string) bool {
+	return sourceKey(a) == sourceKey(b)
+}
+
+// UpdateAvailable reports whether the ref md was installed from now points
+// to a different commit than the installed one. Installs that can't move
+// (archives, local copies, pinned commits, or without a recorded commit)
+// never have updates. The check runs in the background, so it never prompts
+// for credentials, and sources the policy denies aren't contacted.
+func UpdateAvailable(ctx context.Context, md Metadata, opts Options) (bool, error) {
+	if md.Kind != "" || md.Commit == "" || md.Ref == md.Commit {
+		return false, nil
+	}
+	url := md.URL
+	if url == "" {
+		var err error
+		if url, err = gitutil.NormalizeSourceToGitURL(md.Source); err != nil {
+			return false, err
+		}
+	}
+	if err := opts.Policy.CheckURL(url); err != nil {
+		return false, err
+	}
+	latest, err := opts.git().RemoteCommit(ctx, url, md.Ref, gitutil.RemoteOptions{Tokens: opts.Tokens, NonInteractive: true})
+	if err != nil {
+		return false, err
+	}
+	return latest != md.Commit, nil
+}
+
+// OrphanedMetadata returns the folders that have install metadata in targetDir
+// but no skill folder, e.g. after a skill was deleted by hand.
+func OrphanedMetadata(targetDir string) ([]string, error) {
+	base, err := filepath.Abs(expandHome(targetDir))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(base, MetadataDirName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var out []string
+	for _, e := range entries {
+		folder, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(base, folder)); errors.Is(err, os.ErrNotExist) {
+			out = append(out, folder)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
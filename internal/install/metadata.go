@@ -0,0 +1,100 @@
+package install
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+)
+
+// MetadataDirName is the directory inside the target dir that holds install
+// metadata, one JSON file per installed skill folder.
+const MetadataDirName = ".skulls"
+
+// Source kinds recorded in Metadata.Kind. Installs from git leave it empty.
+const (
+	KindArchive = "archive"
+	KindLocal   = "local"
+)
+
+// Metadata records where an installed skill came from.
+type Metadata struct {
+	SkillID string `json:"skill_id"`
+	Source  string `json:"source"`
+	URL     string `json:"url,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+	// Kind is KindArchive or KindLocal for sources that weren't cloned.
+	Kind string `json:"kind,omitempty"`
+	// SHA256 is the checksum of the archive, for archive sources.
+	SHA256 string `json:"sha256,omitempty"`
+	// Path is the skill directory relative to the repository root.
+	Path string `json:"path,omitempty"`
+
+	// Signer and SignerKey are set when the install was signature-verified.
+	Signer    string `json:"signer,omitempty"`
+	SignerKey string `json:"signer_key,omitempty"`
+
+	// Digest and Files fingerprint the installed folder (see DigestDir) so
+	// later edits can be detected.
+	Digest string            `json:"digest,omitempty"`
+	Files  map[string]string `json:"files,omitempty"`
+
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// ReadMetadata loads the metadata recorded for an installed skill folder.
+func ReadMetadata(targetDir string, folder string) (Metadata, error) {
+	b, err := os.ReadFile(metadataPath(expandHome(targetDir), folder))
+	if err != nil {
+		return Metadata{}, err
+	}
+	var md Metadata
+	if err := json.Unmarshal(b, &md); err != nil {
+		return Metadata{}, err
+	}
+	return md, nil
+}
+
+// ReinstallSource returns the source to reinstall this skill from. Sources
+// that no longer resolve to the recorded URL (e.g. relative paths used from
+// another working directory) fall back to the URL itself.
+func (md Metadata) ReinstallSource() string {
+	src := strings.TrimSpace(md.Source)
+	if md.URL == "" {
+		return src
+	}
+	if u, err := gitutil.NormalizeSourceToGitURL(src); err != nil || u != md.URL {
+		return md.URL
+	}
+	return src
+}
+
+// ReinstallRef returns the ref that reproduces the recorded install: the
+// exact commit when known, otherwise the requested ref.
+func (md Metadata) ReinstallRef() string {
+	if md.Commit != "" {
+		return md.Commit
+	}
+	return md.Ref
+}
+
+func writeMetadata(targetBase string, folder string, md Metadata) error {
+	p := metadataPath(targetBase, folder)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	return os.WriteFile(p, b, 0o644)
+}
+
+func metadataPath(targetBase string, folder string) string {
+	return filepath.Join(targetBase, MetadataDirName, folder+".json")
+}
@@ -27,7 +27,7 @@ func resolveSkillDir(repoDir string, skillID string) (string, error) {
 		}
 	}
 
-	skills, err := discoverSkillsInRepo(repoDir, discoverOptions{})
+	skills, err := discoverSkillsInRepo(repoDir, scanOptions{})
 	if err != nil {
 		return "", fmt.Errorf("skill directory not found in repo: %s", filepath.ToSlash(filepath.Join("skills", skillID)))
 	}
@@ -0,0 +1,72 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// ErrNoDigest is returned by VerifyInstalled when the skill's metadata has no
+// recorded digest to compare against.
+var ErrNoDigest = errors.New("no digest recorded at install time")
+
+// VerifyReport lists the differences between an installed skill folder and
+// the digest recorded when it was installed.
+type VerifyReport struct {
+	Folder   string
+	Metadata Metadata
+
+	Modified []string
+	Added    []string
+	Deleted  []string
+}
+
+// Clean reports whether the folder matches the recorded digest.
+func (r VerifyReport) Clean() bool {
+	return len(r.Modified) == 0 && len(r.Added) == 0 && len(r.Deleted) == 0
+}
+
+// VerifyInstalled recomputes the digest of targetDir/folder and compares it to
+// the one recorded in its install metadata.
+func VerifyInstalled(targetDir string, folder string) (VerifyReport, error) {
+	base, err := filepath.Abs(expandHome(targetDir))
+	if err != nil {
+		return VerifyReport{}, err
+	}
+	md, err := ReadMetadata(base, folder)
+	if err != nil {
+		return VerifyReport{}, fmt.Errorf("no install metadata for %s: %w", folder, err)
+	}
+	report := VerifyReport{Folder: folder, Metadata: md}
+	if md.Digest == "" {
+		return report, ErrNoDigest
+	}
+
+	current, err := DigestDir(filepath.Join(base, folder))
+	if err != nil {
+		return report, err
+	}
+	if current.Sum == md.Digest {
+		return report, nil
+	}
+
+	for p, sum := range current.Files {
+		recorded, ok := md.Files[p]
+		switch {
+		case !ok:
+			report.Added = append(report.Added, p)
+		case recorded != sum:
+			report.Modified = append(report.Modified, p)
+		}
+	}
+	for p := range md.Files {
+		if _, ok := current.Files[p]; !ok {
+			report.Deleted = append(report.Deleted, p)
+		}
+	}
+	sort.Strings(report.Modified)
+	sort.Strings(report.Added)
+	sort.Strings(report.Deleted)
+	return report, nil
+}
@@ -0,0 +1,159 @@
+package install
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, string(out))
+	}
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestVerifyInstalled_ReportsModifiedAddedDeleted(t *testing.T) {
+	tmp := t.TempDir()
+	repo := filepath.Join(tmp, "repo")
+	writeFiles(t, repo, map[string]string{
+		"skills/demo/SKILL.md":       "---\nname: demo\ndescription: d\n---\n",
+		"skills/demo/notes.md":       "notes",
+		"skills/demo/scripts/run.sh": "echo hi",
+	})
+	gitRun(t, repo, "init")
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-m", "init")
+
+	target := filepath.Join(tmp, "target")
+	installed, err := InstallSkill(context.Background(), repo, "demo", Options{TargetDir: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := VerifyInstalled(target, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Clean() {
+		t.Fatalf("expected fresh install to verify, got %+v", report)
+	}
+
+	writeFiles(t, installed, map[string]string{
+		"notes.md": "edited by an agent",
+		"extra.md": "new file",
+	})
+	if err := os.Remove(filepath.Join(installed, "scripts", "run.sh")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = VerifyInstalled(target, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Modified, []string{"notes.md"}) {
+		t.Fatalf("modified=%v", report.Modified)
+	}
+	if !reflect.DeepEqual(report.Added, []string{"extra.md"}) {
+		t.Fatalf("added=%v", report.Added)
+	}
+	if !reflect.DeepEqual(report.Deleted, []string{"scripts/run.sh"}) {
+		t.Fatalf("deleted=%v", report.Deleted)
+	}
+}
+
+func TestVerifyInstalled_ReinstallRestoresRecordedCommit(t *testing.T) {
+	tmp := t.TempDir()
+	repo := filepath.Join(tmp, "repo")
+	writeFiles(t, repo, map[string]string{
+		"skills/demo/SKILL.md": "---\nname: demo\ndescription: v1\n---\n",
+	})
+	gitRun(t, repo, "init")
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-m", "v1")
+
+	target := filepath.Join(tmp, "target")
+	installed, err := InstallSkill(context.Background(), repo, "demo", Options{TargetDir: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Upstream moves on, and the installed copy gets edited.
+	writeFiles(t, repo, map[string]string{
+		"skills/demo/SKILL.md": "---\nname: demo\ndescription: v2\n---\n",
+	})
+	gitRun(t, repo, "commit", "-am", "v2")
+	writeFiles(t, installed, map[string]string{"SKILL.md": "tampered"})
+
+	md, err := ReadMetadata(target, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallSkill(context.Background(), md.ReinstallSource(), md.SkillID, Options{TargetDir: target, Force: true, Ref: md.ReinstallRef()}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(installed, "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "---\nname: demo\ndescription: v1\n---\n" {
+		t.Fatalf("expected recorded version, got %q", string(b))
+	}
+	report, err := VerifyInstalled(target, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Clean() {
+		t.Fatalf("expected restored install to verify, got %+v", report)
+	}
+}
+
+func TestDigestDir_IsDeterministic(t *testing.T) {
+	a := t.TempDir()
+	b := t.TempDir()
+	files := map[string]string{"SKILL.md": "x", "sub/a.txt": "a", "sub/b.txt": "b"}
+	writeFiles(t, a, files)
+	writeFiles(t, b, files)
+
+	da, err := DigestDir(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := DigestDir(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if da.Sum != db.Sum {
+		t.Fatalf("expected equal digests, got %s vs %s", da.Sum, db.Sum)
+	}
+
+	writeFiles(t, b, map[string]string{"sub/a.txt": "changed"})
+	db, err = DigestDir(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if da.Sum == db.Sum {
+		t.Fatalf("expected digest to change")
+	}
+}
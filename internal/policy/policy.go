@@ -0,0 +1,188 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+)
+
+// ErrDenied is matched (via errors.Is) by every policy rejection.
+var ErrDenied = errors.New("source denied by policy")
+
+// Rule matches a normalized git URL. Every non-empty field must match.
+//
+// Host and Owner are case-insensitive globs; URL is a glob over the whole
+// normalized URL, where `*` also matches `/`.
+type Rule struct {
+	Host  string `json:"host,omitempty"`
+	Owner string `json:"owner,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// Policy restricts which sources may be installed from.
+//
+// Deny rules always win. When Allow is non-empty, a source must also match at
+// least one allow rule.
+type Policy struct {
+	Allow []Rule `json:"allow,omitempty"`
+	Deny  []Rule `json:"deny,omitempty"`
+
+	// HideDenied hides denied skills in search results instead of marking them.
+	HideDenied bool `json:"hide_denied,omitempty"`
+}
+
+// Set is a stack of policies (e.g. system-wide + user config). A source must
+// be allowed by every policy in the set.
+type Set []Policy
+
+// DeniedError describes why a source was rejected.
+type DeniedError struct {
+	Source string
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("source %s is not allowed by policy: %s", e.Source, e.Reason)
+}
+
+func (e *DeniedError) Is(target error) bool {
+	return target == ErrDenied
+}
+
+// Load reads a policy JSON file. A missing file yields an empty policy.
+func Load(path string) (Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Policy{}, nil
+		}
+		return Policy{}, err
+	}
+	var p Policy
+	if err := json.Unmarshal(b, &p); err != nil {
+		return Policy{}, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// IsEmpty reports whether the policy has no rules.
+func (p Policy) IsEmpty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// CheckURL evaluates a normalized git URL (see gitutil.NormalizeSourceToGitURL).
+func (p Policy) CheckURL(gitURL string) error {
+	remote := gitutil.ParseRemote(gitURL)
+	for _, r := range p.Deny {
+		if r.matches(gitURL, remote) {
+			return &DeniedError{Source: gitURL, Reason: "matches deny rule " + r.String()}
+		}
+	}
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	for _, r := range p.Allow {
+		if r.matches(gitURL, remote) {
+			return nil
+		}
+	}
+	return &DeniedError{Source: gitURL, Reason: "no allow rule matches"}
+}
+
+// CheckURL evaluates a normalized git URL against every policy in the set.
+func (s Set) CheckURL(gitURL string) error {
+	for _, p := range s {
+		if err := p.CheckURL(gitURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check normalizes source and evaluates it against every policy in the set.
+func (s Set) Check(source string) error {
+	if len(s) == 0 {
+		return nil
+	}
+	gitURL, err := gitutil.NormalizeSourceToGitURL(source)
+	if err != nil {
+		return err
+	}
+	return s.CheckURL(gitURL)
+}
+
+// HideDenied reports whether any policy asks for denied skills to be hidden.
+func (s Set) HideDenied() bool {
+	for _, p := range s {
+		if p.HideDenied {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) String() string {
+	parts := []string{}
+	if r.Host != "" {
+		parts = append(parts, "host="+r.Host)
+	}
+	if r.Owner != "" {
+		parts = append(parts, "owner="+r.Owner)
+	}
+	if r.URL != "" {
+		parts = append(parts, "url="+r.URL)
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+func (r Rule) matches(gitURL string, remote gitutil.Remote) bool {
+	if r.Host == "" && r.Owner == "" && r.URL == "" {
+		return false
+	}
+	if r.Host != "" && !globMatch(strings.ToLower(r.Host), remote.Host) {
+		return false
+	}
+	if r.Owner != "" && !globMatch(strings.ToLower(r.Owner), strings.ToLower(remote.Owner)) {
+		return false
+	}
+	if r.URL != "" && !globMatch(r.URL, gitURL) && !globMatch(r.URL, strings.TrimSuffix(gitURL, ".git")) {
+		return false
+	}
+	return true
+}
+
+// globMatch matches s against a pattern where `*` matches any run of
+// characters (including `/`) and `?` matches a single character.
+func globMatch(pattern string, s string) bool {
+	if s == "" {
+		return false
+	}
+	p, str := []rune(pattern), []rune(s)
+	// On a mismatch, backtrack to the last `*` and let it swallow one more
+	// character; earlier stars never need revisiting.
+	pi, si := 0, 0
+	star, starSi := -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case pi < len(p) && p[pi] == '*':
+			star, starSi = pi, si
+			pi++
+		case star >= 0:
+			starSi++
+			pi, si = star+1, starSi
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
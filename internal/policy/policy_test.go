@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicy_CheckURL(t *testing.T) {
+	p := Policy{
+		Allow: []Rule{
+			{Owner: "acme"},
+			{Host: "git.internal.example"},
+			{URL: "https://github.com/vercel-labs/*"},
+		},
+		Deny: []Rule{
+			{URL: "*/acme/experimental*"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		allowed bool
+	}{
+		{"owner_https", "https://github.com/acme/skills.git", true},
+		{"owner_case_insensitive", "https://github.com/ACME/skills.git", true},
+		{"owner_ssh", "git@github.com:acme/skills.git", true},
+		{"host", "https://git.internal.example/team/skills.git", true},
+		{"host_ssh_url", "ssh://git@git.internal.example:2222/team/skills.git", true},
+		{"url_glob", "https://github.com/vercel-labs/agent-skills.git", true},
+		{"deny_wins", "https://github.com/acme/experimental-skills.git", false},
+		{"not_allowed", "https://github.com/someone/skills.git", false},
+		{"local_path", "/home/me/skills", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.CheckURL(tt.url)
+			if tt.allowed && err != nil {
+				t.Fatalf("expected allowed, got %v", err)
+			}
+			if !tt.allowed {
+				if err == nil {
+					t.Fatalf("expected denied")
+				}
+				if !errors.Is(err, ErrDenied) {
+					t.Fatalf("expected ErrDenied, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicy_EmptyAllowsEverything(t *testing.T) {
+	if err := (Policy{}).CheckURL("https://github.com/any/repo.git"); err != nil {
+		t.Fatalf("expected empty policy to allow, got %v", err)
+	}
+}
+
+func TestSet_AllPoliciesMustAllow(t *testing.T) {
+	s := Set{
+		{Allow: []Rule{{Host: "github.com"}}},
+		{Deny: []Rule{{Owner: "evil"}}},
+	}
+
+	if err := s.Check("good/repo"); err != nil {
+		t.Fatalf("expected allowed, got %v", err)
+	}
+	if err := s.Check("evil/repo"); !errors.Is(err, ErrDenied) {
+		t.Fatalf("expected user deny to apply, got %v", err)
+	}
+	if err := s.Check("https://gitlab.com/good/repo.git"); !errors.Is(err, ErrDenied) {
+		t.Fatalf("expected system allowlist to apply, got %v", err)
+	}
+}
+
+func TestLoad_MissingFileIsEmpty(t *testing.T) {
+	p, err := Load(filepath.Join(t.TempDir(), "nope.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsEmpty() {
+		t.Fatalf("expected empty policy, got %+v", p)
+	}
+}
+
+func TestLoad_ParsesRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	body := `{"allow":[{"owner":"acme"}],"deny":[{"host":"evil.example"}],"hide_denied":true}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Allow) != 1 || p.Allow[0].Owner != "acme" {
+		t.Fatalf("allow=%+v", p.Allow)
+	}
+	if len(p.Deny) != 1 || p.Deny[0].Host != "evil.example" {
+		t.Fatalf("deny=%+v", p.Deny)
+	}
+	if !p.HideDenied {
+		t.Fatalf("expected hide_denied")
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"github.com", "github.com", true},
+		{"*.example.com", "git.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"https://github.com/acme/*", "https://github.com/acme/a/b.git", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyyb", false},
+		{"gh?", "ghe", true},
+		{"gh?", "gh", false},
+		{"é?", "éa", true},
+		{"*", "", false},
+		{"a.b", "axb", false},
+	}
+	for _, c := range cases {
+		if got := globMatch(c.pattern, c.s); got != c.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", c.pattern, c.s, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package skillsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Catalog is a self-hosted skills registry speaking the skills.sh search API:
+//
+//	GET <base>/api/search?q=<query>&limit=<n>  -> {"skills": [...]}
+//	GET <base>/api/popular?limit=<n>           -> {"skills": [...]}
+//
+// Previews are fetched from the skill's source like Client does.
+type Catalog struct {
+	Label   string
+	BaseURL string
+	HTTP    *http.Client
+
+	// GitHubToken and GitHubHosts are used for previews, as in Client.
+	GitHubToken string
+	GitHubHosts []GitHubHost
+}
+
+func (c Catalog) Name() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return Client{BaseURL: c.BaseURL}.Name()
+}
+
+func (c Catalog) Search(ctx context.Context, query string, limit int) ([]Skill, error) {
+	return Client{BaseURL: c.BaseURL, HTTP: c.HTTP}.Search(ctx, query, limit)
+}
+
+func (c Catalog) Popular(ctx context.Context, limit int) ([]Skill, error) {
+	u, err := url.Parse(strings.TrimSpace(c.BaseURL))
+	if err != nil {
+		return nil, err
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/api/popular"
+	if limit > 0 {
+		q := u.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		u.RawQuery = q.Encode()
+	}
+
+	b, err := httpGet(ctx, c.HTTP, u.String())
+	if err != nil {
+		return nil, fmt.Errorf("popular fetch failed: %w", err)
+	}
+	var decoded searchResponse
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded.Skills, nil
+}
+
+func (c Catalog) FetchSkillMarkdown(ctx context.Context, skill Skill) (string, error) {
+	return Client{HTTP: c.HTTP, GitHubToken: c.GitHubToken, GitHubHosts: c.GitHubHosts}.FetchSkillMarkdown(ctx, skill)
+}
+
+func (c Catalog) ListSkillFiles(ctx context.Context, skill Skill) (SkillFiles, error) {
+	return Client{HTTP: c.HTTP, GitHubToken: c.GitHubToken, GitHubHosts: c.GitHubHosts}.ListSkillFiles(ctx, skill)
+}
+
+func (c Catalog) FetchSkillFile(ctx context.Context, skill Skill, repoPath string) (string, error) {
+	return Client{HTTP: c.HTTP, GitHubToken: c.GitHubToken, GitHubHosts: c.GitHubHosts}.FetchSkillFile(ctx, skill, repoPath)
+}
+
+func httpGet(ctx context.Context, httpClient *http.Client, u string) ([]byte, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,102 @@
+package skillsapi
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SkillFiles lists the files in a skill's directory.
+type SkillFiles struct {
+	// Dir is the skill directory relative to the repository root ("" for a
+	// skill at the root).
+	Dir string
+	// Files are the paths under Dir, relative to it, sorted.
+	Files []string
+}
+
+// FileBrowser is implemented by registries that can list and fetch every file
+// of a skill, not just its SKILL.md.
+type FileBrowser interface {
+	ListSkillFiles(ctx context.Context, skill Skill) (SkillFiles, error)
+	// FetchSkillFile returns the file at repoPath (relative to the repository
+	// root) from the skill's source.
+	FetchSkillFile(ctx context.Context, skill Skill, repoPath string) (string, error)
+}
+
+// ListSkillFiles lists the files of the skill's directory on its code host.
+// The directory is skill.Path when set, then skills/<skillID>, then the
+// SKILL.md whose frontmatter names the skill, as in FetchSkillMarkdown.
+func (c Client) ListSkillFiles(ctx context.Context, skill Skill) (SkillFiles, error) {
+	skillID := strings.TrimSpace(skill.SkillID)
+	provider, ok := c.previewProvider(skill.Source, c.previewHTTP())
+	if !ok {
+		return SkillFiles{}, ErrPreviewUnavailable
+	}
+	if skillID == "" {
+		return SkillFiles{}, fmt.Errorf("%w: empty skill id", ErrPreviewUnavailable)
+	}
+
+	all, err := listRepoFiles(ctx, provider)
+	if err != nil {
+		return SkillFiles{}, err
+	}
+	dir, err := locateSkillDir(ctx, provider, all, skill)
+	if err != nil {
+		return SkillFiles{}, err
+	}
+	return SkillFiles{Dir: dir, Files: filesUnder(all, dir)}, nil
+}
+
+// FetchSkillFile fetches one file from the skill's repository.
+func (c Client) FetchSkillFile(ctx context.Context, skill Skill, repoPath string) (string, error) {
+	provider, ok := c.previewProvider(skill.Source, c.previewHTTP())
+	if !ok {
+		return "", ErrPreviewUnavailable
+	}
+	body, _, err := provider.fetchFile(ctx, strings.TrimPrefix(repoPath, "/"))
+	return body, err
+}
+
+func locateSkillDir(ctx context.Context, provider previewProvider, all []string, skill Skill) (string, error) {
+	skillID := strings.TrimSpace(skill.SkillID)
+	if p := strings.TrimSpace(skill.Path); p != "" {
+		if dir := repoDir(p); slices.Contains(all, path.Join(dir, "SKILL.md")) {
+			return dir, nil
+		}
+	}
+	if dir := path.Join("skills", skillID); slices.Contains(all, path.Join(dir, "SKILL.md")) {
+		return dir, nil
+	}
+
+	p, _, err := findSkillMarkdown(ctx, provider, all, skillID)
+	if err != nil {
+		return "", err
+	}
+	return repoDir(path.Dir(p)), nil
+}
+
+// repoDir cleans a directory path relative to the repository root, with ""
+// for the root itself.
+func repoDir(p string) string {
+	return strings.Trim(path.Clean("/"+p), "/")
+}
+
+// filesUnder returns the paths in all below dir, relative to it.
+func filesUnder(all []string, dir string) []string {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	var files []string
+	for _, p := range all {
+		if rel, ok := strings.CutPrefix(p, prefix); ok && rel != "" {
+			files = append(files, rel)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,109 @@
+package skillsapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_ListSkillFiles_FindsSkillDirViaFrontmatter(t *testing.T) {
+	rawSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/owner/repo/HEAD/plugins/pdf/SKILL.md":
+			_, _ = w.Write([]byte("---\nname: pdf-tools\ndescription: x\n---\n# PDF\n"))
+		case "/owner/repo/HEAD/plugins/pdf/scripts/fill.py":
+			_, _ = w.Write([]byte("print('hi')\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer rawSrv.Close()
+
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/git/trees/HEAD" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tree":[
+			{"path":"README.md","type":"blob"},
+			{"path":"plugins/pdf","type":"tree"},
+			{"path":"plugins/pdf/SKILL.md","type":"blob"},
+			{"path":"plugins/pdf/scripts/fill.py","type":"blob"},
+			{"path":"plugins/pdf-extra/SKILL.md","type":"blob"}
+		]}`))
+	}))
+	defer apiSrv.Close()
+
+	c := Client{GitHubRawBase: rawSrv.URL, GitHubAPIBase: apiSrv.URL, HTTP: &http.Client{Timeout: 2 * time.Second}}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	skill := Skill{SkillID: "pdf-tools", Source: "owner/repo"}
+	files, err := c.ListSkillFiles(ctx, skill)
+	if err != nil {
+		t.Fatalf("ListSkillFiles: %v", err)
+	}
+	if files.Dir != "plugins/pdf" {
+		t.Fatalf("expected dir plugins/pdf, got %q", files.Dir)
+	}
+	if want := []string{"SKILL.md", "scripts/fill.py"}; !slices.Equal(files.Files, want) {
+		t.Fatalf("expected files %v, got %v", want, files.Files)
+	}
+
+	body, err := c.FetchSkillFile(ctx, skill, "plugins/pdf/scripts/fill.py")
+	if err != nil {
+		t.Fatalf("FetchSkillFile: %v", err)
+	}
+	if body != "print('hi')\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestClient_ListSkillFiles_UsesIndexedPathAtRepoRoot(t *testing.T) {
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tree":[
+			{"path":"SKILL.md","type":"blob"},
+			{"path":"notes.txt","type":"blob"}
+		]}`))
+	}))
+	defer apiSrv.Close()
+
+	// No raw host: a known Path must not need any SKILL.md fetches.
+	c := Client{GitHubRawBase: "http://127.0.0.1:0", GitHubAPIBase: apiSrv.URL, HTTP: &http.Client{Timeout: 2 * time.Second}}
+	files, err := c.ListSkillFiles(context.Background(), Skill{SkillID: "root", Source: "owner/repo", Path: "."})
+	if err != nil {
+		t.Fatalf("ListSkillFiles: %v", err)
+	}
+	if files.Dir != "" {
+		t.Fatalf("expected root dir, got %q", files.Dir)
+	}
+	if want := []string{"SKILL.md", "notes.txt"}; !slices.Equal(files.Files, want) {
+		t.Fatalf("expected files %v, got %v", want, files.Files)
+	}
+}
+
+func TestClient_FetchSkillFile_RejectsLargeFiles(t *testing.T) {
+	big := strings.Repeat("x", MaxPreviewFileSize+1)
+	rawSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/owner/repo/HEAD/chunked.bin" {
+			// Flushing before writing leaves the length unknown.
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(big))
+	}))
+	defer rawSrv.Close()
+
+	c := Client{GitHubRawBase: rawSrv.URL, HTTP: &http.Client{Timeout: 2 * time.Second}}
+	skill := Skill{SkillID: "big", Source: "owner/repo"}
+	for _, p := range []string{"big.bin", "chunked.bin"} {
+		if _, err := c.FetchSkillFile(context.Background(), skill, p); err == nil || !strings.Contains(err.Error(), "too large") {
+			t.Fatalf("%s: expected a too large error, got %v", p, err)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package skillsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxListingPages caps paginated tree listings for previews.
+const maxListingPages = 20
+
+// parseGitLabProject recognizes gitlab:group/repo and gitlab.com URLs,
+// returning the project path (which may include subgroups).
+func parseGitLabProject(source string) (string, bool) {
+	p, ok := forgePath(source, "gitlab:", "gitlab.com")
+	if !ok || len(strings.Split(p, "/")) < 2 {
+		return "", false
+	}
+	return p, true
+}
+
+// parseBitbucketRepo recognizes bitbucket:owner/repo and bitbucket.org URLs.
+func parseBitbucketRepo(source string) (owner, repo string, ok bool) {
+	p, ok := forgePath(source, "bitbucket:", "bitbucket.org")
+	if !ok {
+		return "", "", false
+	}
+	parts := strings.Split(p, "/")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// parseGiteaRepo recognizes the gitea:host/owner/repo shorthand. Gitea and
+// Forgejo are self-hosted, so plain URLs can't be told apart from other hosts.
+func parseGiteaRepo(source string) (host, owner, repo string, ok bool) {
+	s := strings.TrimSpace(source)
+	if !strings.HasPrefix(s, "gitea:") {
+		return "", "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.Trim(strings.TrimPrefix(s, "gitea:"), "/"), ".git"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return strings.ToLower(parts[0]), parts[1], parts[2], true
+}
+
+// forgePath returns the repository path of source when it's written as
+// <prefix>path, https://<host>/path or git@<host>:path.
+func forgePath(source string, prefix string, host string) (string, bool) {
+	s := strings.TrimSpace(source)
+	var p string
+	switch {
+	case strings.HasPrefix(s, prefix):
+		p = strings.TrimPrefix(s, prefix)
+	case strings.HasPrefix(s, "git@"+host+":"):
+		p = strings.TrimPrefix(s, "git@"+host+":")
+	case strings.Contains(s, "://"):
+		u, err := url.Parse(s)
+		if err != nil || !strings.EqualFold(u.Hostname(), host) {
+			return "", false
+		}
+		p = u.Path
+	default:
+		return "", false
+	}
+	p = strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+	for _, part := range strings.Split(p, "/") {
+		if part == "" {
+			return "", false
+		}
+	}
+	return p, p != ""
+}
+
+func defaultBase(base string, fallback string) string {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	if base == "" {
+		return fallback
+	}
+	return base
+}
+
+// getPreviewJSON decodes a JSON response into v and returns its headers.
+func getPreviewJSON(ctx context.Context, httpClient *http.Client, u string, v any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return resp.Header, json.NewDecoder(resp.Body).Decode(v)
+}
+
+func getPreviewFile(ctx context.Context, httpClient *http.Client, u string) (string, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", ErrPreviewUnavailable, err)
+	}
+	return doPreviewRequest(httpClient, req)
+}
+
+// escapePath escapes each segment of p, keeping the slashes.
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, s := range parts {
+		parts[i] = url.PathEscape(s)
+	}
+	return strings.Join(parts, "/")
+}
+
+// gitlabPreview uses the GitLab v4 repository API.
+type gitlabPreview struct {
+	http    *http.Client
+	base    string
+	project string
+}
+
+func (g gitlabPreview) projectURL() string {
+	return g.base + "/api/v4/projects/" + url.PathEscape(g.project)
+}
+
+func (g gitlabPreview) fetchFile(ctx context.Context, relPath string) (string, int, error) {
+	return getPreviewFile(ctx, g.http, g.projectURL()+"/repository/files/"+url.PathEscape(relPath)+"/raw")
+}
+
+func (g gitlabPreview) listFiles(ctx context.Context) ([]string, error) {
+	var out []string
+	page := "1"
+	for i := 0; i < maxListingPages && page != ""; i++ {
+		q := url.Values{"recursive": {"true"}, "per_page": {"100"}, "page": {page}}
+		var items []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		}
+		h, err := getPreviewJSON(ctx, g.http, g.projectURL()+"/repository/tree?"+q.Encode(), &items)
+		if err != nil {
+			return nil, fmt.Errorf("tree listing failed: %w", err)
+		}
+		for _, it := range items {
+			if it.Type == "blob" {
+				out = append(out, it.Path)
+			}
+		}
+		page = h.Get("X-Next-Page")
+	}
+	return out, nil
+}
+
+// bitbucketPreview uses the Bitbucket Cloud 2.0 API. Its src endpoints need
+// a branch, so the main branch is looked up first.
+type bitbucketPreview struct {
+	http        *http.Client
+	apiBase     string
+	owner, repo string
+
+	branch string
+}
+
+func (b *bitbucketPreview) repoURL() string {
+	return b.apiBase + "/2.0/repositories/" + url.PathEscape(b.owner) + "/" + url.PathEscape(b.repo)
+}
+
+func (b *bitbucketPreview) mainBranch(ctx context.Context) (string, error) {
+	if b.branch != "" {
+		return b.branch, nil
+	}
+	var decoded struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if _, err := getPreviewJSON(ctx, b.http, b.repoURL(), &decoded); err != nil {
+		return "", err
+	}
+	if decoded.MainBranch.Name == "" {
+		return "", fmt.Errorf("%s/%s has no main branch", b.owner, b.repo)
+	}
+	b.branch = decoded.MainBranch.Name
+	return b.branch, nil
+}
+
+func (b *bitbucketPreview) fetchFile(ctx context.Context, relPath string) (string, int, error) {
+	branch, err := b.mainBranch(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", ErrPreviewUnavailable, err)
+	}
+	return getPreviewFile(ctx, b.http, b.repoURL()+"/src/"+url.PathEscape(branch)+"/"+escapePath(relPath))
+}
+
+func (b *bitbucketPreview) listFiles(ctx context.Context) ([]string, error) {
+	branch, err := b.mainBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{"max_depth": {"10"}, "pagelen": {"100"}}
+	next := b.repoURL() + "/src/" + url.PathEscape(branch) + "/?" + q.Encode()
+
+	var out []string
+	for i := 0; i < maxListingPages && next != ""; i++ {
+		var decoded struct {
+			Values []struct {
+				Path string `json:"path"`
+				Type string `json:"type"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		if _, err := getPreviewJSON(ctx, b.http, next, &decoded); err != nil {
+			return nil, fmt.Errorf("tree listing failed: %w", err)
+		}
+		for _, v := range decoded.Values {
+			if v.Type == "commit_file" {
+				out = append(out, v.Path)
+			}
+		}
+		next = decoded.Next
+	}
+	return out, nil
+}
+
+// giteaPreview uses the Gitea API, which Forgejo shares.
+type giteaPreview struct {
+	http        *http.Client
+	base        string
+	owner, repo string
+}
+
+func (g giteaPreview) repoURL() string {
+	return g.base + "/api/v1/repos/" + url.PathEscape(g.owner) + "/" + url.PathEscape(g.repo)
+}
+
+func (g giteaPreview) fetchFile(ctx context.Context, relPath string) (string, int, error) {
+	return getPreviewFile(ctx, g.http, g.repoURL()+"/raw/"+escapePath(relPath))
+}
+
+func (g giteaPreview) listFiles(ctx context.Context) ([]string, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if _, err := getPreviewJSON(ctx, g.http, g.repoURL(), &repo); err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for page := 1; page <= maxListingPages; page++ {
+		q := url.Values{"recursive": {"true"}, "per_page": {"1000"}, "page": {strconv.Itoa(page)}}
+		var decoded struct {
+			Tree []struct {
+				Path string `json:"path"`
+				Type string `json:"type"`
+			} `json:"tree"`
+			Truncated bool `json:"truncated"`
+		}
+		u := g.repoURL() + "/git/trees/" + url.PathEscape(repo.DefaultBranch) + "?" + q.Encode()
+		if _, err := getPreviewJSON(ctx, g.http, u, &decoded); err != nil {
+			return nil, fmt.Errorf("tree listing failed: %w", err)
+		}
+		for _, it := range decoded.Tree {
+			if it.Type == "blob" {
+				out = append(out, it.Path)
+			}
+		}
+		if !decoded.Truncated {
+			break
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,118 @@
+package skillsapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRateLimited is matched (via errors.Is) by RateLimitError.
+var ErrRateLimited = errors.New("GitHub rate limit exceeded")
+
+// RateLimitError is returned when GitHub refuses a request because the rate
+// limit is exhausted.
+type RateLimitError struct {
+	// Reset is when the limit resets; zero if GitHub didn't say.
+	Reset time.Time
+	// Authenticated is true if the request carried a token.
+	Authenticated bool
+}
+
+func (e *RateLimitError) Error() string {
+	msg := ErrRateLimited.Error()
+	if !e.Reset.IsZero() {
+		msg += "; resets at " + e.Reset.Local().Format("15:04")
+	}
+	if !e.Authenticated {
+		msg += " (set GITHUB_TOKEN or run `gh auth login` for a higher limit)"
+	}
+	return msg
+}
+
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+// newGitHubRequest builds a GET request to a GitHub API or raw URL, adding the
+// token if there is one. Callers only pass URLs under the configured GitHub
+// bases, so the token never leaves those hosts (http.Client drops it on
+// cross-host redirects).
+func newGitHubRequest(ctx context.Context, u string, token string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token = strings.TrimSpace(token); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// rateLimitError returns a *RateLimitError if resp is a GitHub rate-limit
+// rejection, nil otherwise.
+func rateLimitError(resp *http.Response) error {
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && (resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""))
+	if !limited {
+		return nil
+	}
+
+	e := &RateLimitError{Authenticated: resp.Request != nil && resp.Request.Header.Get("Authorization") != ""}
+	if v, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		e.Reset = time.Unix(v, 0)
+	} else if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		e.Reset = time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return e
+}
+
+// GitHubHost is a GitHub Enterprise instance. APIBase and RawBase default to
+// https://<host>/api/v3 and https://<host>/raw. Token is sent only to those
+// two bases.
+type GitHubHost struct {
+	Host    string
+	APIBase string
+	RawBase string
+	Token   string
+}
+
+func hasGitHubHost(hosts []GitHubHost, host string) bool {
+	_, ok := findGitHubHost(hosts, host)
+	return ok
+}
+
+func findGitHubHost(hosts []GitHubHost, host string) (GitHubHost, bool) {
+	for _, h := range hosts {
+		if strings.EqualFold(strings.TrimSpace(h.Host), host) {
+			return h, true
+		}
+	}
+	return GitHubHost{}, false
+}
+
+// githubEndpoints returns the raw and API bases and the token for host, as
+// returned by parseGitHubRepoOn.
+func (c Client) githubEndpoints(host string) (rawBase, apiBase, token string) {
+	if h, ok := findGitHubHost(c.GitHubHosts, host); ok && host != "github.com" {
+		rawBase = strings.TrimRight(strings.TrimSpace(h.RawBase), "/")
+		if rawBase == "" {
+			rawBase = "https://" + host + "/raw"
+		}
+		apiBase = strings.TrimRight(strings.TrimSpace(h.APIBase), "/")
+		if apiBase == "" {
+			apiBase = "https://" + host + "/api/v3"
+		}
+		return rawBase, apiBase, strings.TrimSpace(h.Token)
+	}
+
+	rawBase = strings.TrimRight(strings.TrimSpace(c.GitHubRawBase), "/")
+	if rawBase == "" {
+		rawBase = "https://raw.githubusercontent.com"
+	}
+	apiBase = strings.TrimRight(strings.TrimSpace(c.GitHubAPIBase), "/")
+	if apiBase == "" {
+		apiBase = "https://api.github.com"
+	}
+	return rawBase, apiBase, c.GitHubToken
+}
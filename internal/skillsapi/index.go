@@ -0,0 +1,221 @@
+package skillsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IndexVersion is the current static index format version.
+const IndexVersion = 1
+
+// IndexFile is a static skills index, as written by `skulls index build`:
+//
+//	{
+//	  "version": 1,
+//	  "generated_at": "2026-01-02T15:04:05Z",
+//	  "skills": [
+//	    {"id": "...", "skillId": "...", "name": "...", "description": "...",
+//	     "source": "...", "ref": "...", "path": "...", "tags": ["..."]}
+//	  ]
+//	}
+//
+// A missing version is read as version 1.
+type IndexFile struct {
+	Version     int       `json:"version"`
+	GeneratedAt time.Time `json:"generated_at,omitzero"`
+	Skills      []Skill   `json:"skills"`
+}
+
+// ParseIndex decodes an index, rejecting versions newer than IndexVersion.
+func ParseIndex(b []byte) (IndexFile, error) {
+	var f IndexFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return IndexFile{}, err
+	}
+	if f.Version > IndexVersion {
+		return IndexFile{}, fmt.Errorf("index version %d is newer than supported version %d; upgrade skulls", f.Version, IndexVersion)
+	}
+	if f.Version == 0 {
+		f.Version = IndexVersion
+	}
+	return f, nil
+}
+
+// Index is a registry backed by an IndexFile read from a local file or an
+// http(s) URL. It's loaded on first use and searched locally; a failed load
+// is retried on the next call.
+type Index struct {
+	Label    string
+	Location string
+	HTTP     *http.Client
+
+	// GitHubToken and GitHubHosts are used for previews, as in Client.
+	GitHubToken string
+	GitHubHosts []GitHubHost
+
+	mu     sync.Mutex
+	loaded bool
+	skills []Skill
+}
+
+func (x *Index) Name() string {
+	if x.Label != "" {
+		return x.Label
+	}
+	return x.Location
+}
+
+func (x *Index) Search(ctx context.Context, query string, limit int) ([]Skill, error) {
+	all, err := x.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := strings.ToLower(strings.TrimSpace(query))
+
+	type scored struct {
+		s     Skill
+		score int
+	}
+	var hits []scored
+	for _, s := range all {
+		if score := matchScore(s, q); score > 0 {
+			hits = append(hits, scored{s, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].s.Installs > hits[j].s.Installs
+	})
+
+	out := make([]Skill, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.s)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (x *Index) Popular(ctx context.Context, limit int) ([]Skill, error) {
+	all, err := x.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]Skill(nil), all...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Installs > out[j].Installs })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// FetchSkillMarkdown fetches SKILL.md from the skill's source when possible
+// and otherwise falls back to the description recorded in the index, since
+// internal sources usually aren't on GitHub.
+func (x *Index) FetchSkillMarkdown(ctx context.Context, skill Skill) (string, error) {
+	md, err := Client{HTTP: x.HTTP, GitHubToken: x.GitHubToken, GitHubHosts: x.GitHubHosts}.FetchSkillMarkdown(ctx, skill)
+	if err == nil || strings.TrimSpace(skill.Description) == "" {
+		return md, err
+	}
+	if ctx.Err() != nil {
+		return "", err
+	}
+	return indexSummaryMarkdown(skill), nil
+}
+
+// ListSkillFiles lists the skill's files on its code host; unlike
+// FetchSkillMarkdown there is nothing in the index to fall back to.
+func (x *Index) ListSkillFiles(ctx context.Context, skill Skill) (SkillFiles, error) {
+	return Client{HTTP: x.HTTP, GitHubToken: x.GitHubToken, GitHubHosts: x.GitHubHosts}.ListSkillFiles(ctx, skill)
+}
+
+func (x *Index) FetchSkillFile(ctx context.Context, skill Skill, repoPath string) (string, error) {
+	return Client{HTTP: x.HTTP, GitHubToken: x.GitHubToken, GitHubHosts: x.GitHubHosts}.FetchSkillFile(ctx, skill, repoPath)
+}
+
+func indexSummaryMarkdown(s Skill) string {
+	name := s.Name
+	if name == "" {
+		name = s.SkillID
+	}
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "# %s\n\n%s\n\n", name, s.Description)
+	fmt.Fprintf(&b, "- Source: `%s`\n", s.Source)
+	if s.Ref != "" {
+		fmt.Fprintf(&b, "- Ref: `%s`\n", s.Ref)
+	}
+	if s.Path != "" {
+		fmt.Fprintf(&b, "- Path: `%s`\n", s.Path)
+	}
+	if len(s.Tags) > 0 {
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(s.Tags, ", "))
+	}
+	return b.String()
+}
+
+func (x *Index) load(ctx context.Context) ([]Skill, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.loaded {
+		return x.skills, nil
+	}
+
+	var b []byte
+	var err error
+	loc := strings.TrimSpace(x.Location)
+	if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+		b, err = httpGet(ctx, x.HTTP, loc)
+	} else {
+		b, err = os.ReadFile(loc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load index %s: %w", loc, err)
+	}
+	f, err := ParseIndex(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse index %s: %w", loc, err)
+	}
+	x.skills, x.loaded = f.Skills, true
+	return x.skills, nil
+}
+
+// matchScore ranks how well s matches an already lowercased query; 0 means no
+// match.
+func matchScore(s Skill, q string) int {
+	id := strings.ToLower(s.SkillID)
+	name := strings.ToLower(s.Name)
+	switch {
+	case q == "":
+		return 1
+	case id == q || name == q:
+		return 5
+	case strings.HasPrefix(id, q) || strings.HasPrefix(name, q):
+		return 4
+	case strings.Contains(id, q) || strings.Contains(name, q):
+		return 3
+	case hasTag(s, q):
+		return 2
+	case strings.Contains(strings.ToLower(s.Description), q) || strings.Contains(strings.ToLower(s.Source), q):
+		return 1
+	}
+	return 0
+}
+
+func hasTag(s Skill, q string) bool {
+	for _, t := range s.Tags {
+		if strings.EqualFold(t, q) {
+			return true
+		}
+	}
+	return false
+}
@@ -25,6 +25,10 @@ var shorthandRepoRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
 
 const maxPreviewCandidates = 250
 
+// MaxPreviewFileSize caps the files fetched for previews; bigger ones fail
+// with a "too large" error without being read in full.
+const MaxPreviewFileSize = 256 << 10
+
 type githubTreeResponse struct {
 	Truncated bool `json:"truncated"`
 	Tree      []struct {
@@ -33,39 +37,35 @@ type githubTreeResponse struct {
 	} `json:"tree"`
 }
 
+// previewProvider reads files from one repository on a code host.
+type previewProvider interface {
+	// fetchFile returns relPath on the default branch, with the HTTP status
+	// (404 means the file doesn't exist).
+	fetchFile(ctx context.Context, relPath string) (string, int, error)
+	// listFiles returns the paths of all files in the repository.
+	listFiles(ctx context.Context) ([]string, error)
+}
+
 // FetchSkillMarkdown fetches the raw SKILL.md contents for a skill as best-effort.
 //
-// GitHub-only. Strategy:
+// Supported hosts are GitHub (github.com or one of c.GitHubHosts), GitLab,
+// Bitbucket and Gitea/Forgejo. Strategy:
 //  1. Fast path: try /skills/<skillID>/SKILL.md
-//  2. Fallback: on 404, list repo tree via GitHub API, rank SKILL.md candidates,
+//  2. Fallback: on 404, list the repo tree, rank SKILL.md candidates,
 //     fetch candidates, parse strict frontmatter, and return matching name.
 func (c Client) FetchSkillMarkdown(ctx context.Context, skill Skill) (string, error) {
-	owner, repo, ok := parseGitHubRepo(skill.Source)
+	skillID := strings.TrimSpace(skill.SkillID)
+
+	provider, ok := c.previewProvider(skill.Source, c.previewHTTP())
 	if !ok {
 		return "", ErrPreviewUnavailable
 	}
-	skillID := strings.TrimSpace(skill.SkillID)
 	if skillID == "" {
 		return "", fmt.Errorf("%w: empty skill id", ErrPreviewUnavailable)
 	}
 
-	httpClient := c.HTTP
-	if httpClient == nil {
-		httpClient = &http.Client{Timeout: 10 * time.Second}
-	}
-
-	rawBase := strings.TrimRight(strings.TrimSpace(c.GitHubRawBase), "/")
-	if rawBase == "" {
-		rawBase = "https://raw.githubusercontent.com"
-	}
-
-	apiBase := strings.TrimRight(strings.TrimSpace(c.GitHubAPIBase), "/")
-	if apiBase == "" {
-		apiBase = "https://api.github.com"
-	}
-
 	primaryPath := path.Join("skills", skillID, "SKILL.md")
-	md, status, err := fetchGitHubRaw(ctx, httpClient, rawBase, owner, repo, primaryPath)
+	md, status, err := provider.fetchFile(ctx, primaryPath)
 	if err == nil {
 		return md, nil
 	}
@@ -73,26 +73,88 @@ func (c Client) FetchSkillMarkdown(ctx context.Context, skill Skill) (string, er
 		return "", err
 	}
 
-	paths, treeErr := fetchGitHubTreeSkillMdPaths(ctx, httpClient, apiBase, owner, repo, skillID)
-	if treeErr != nil {
-		return "", fmt.Errorf("%w: %w", ErrPreviewUnavailable, treeErr)
+	all, err := listRepoFiles(ctx, provider)
+	if err != nil {
+		return "", err
+	}
+	_, md, err = findSkillMarkdown(ctx, provider, all, skillID)
+	return md, err
+}
+
+// listRepoFiles lists the repository, wrapping failures other than rate
+// limits in ErrPreviewUnavailable.
+func listRepoFiles(ctx context.Context, provider previewProvider) ([]string, error) {
+	all, err := provider.listFiles(ctx)
+	if errors.Is(err, ErrRateLimited) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPreviewUnavailable, err)
 	}
+	return all, nil
+}
 
-	for _, p := range paths {
-		candidate, _, rawErr := fetchGitHubRaw(ctx, httpClient, rawBase, owner, repo, p)
-		if rawErr != nil {
+// findSkillMarkdown fetches the ranked SKILL.md candidates among all and
+// returns the path and contents of the first whose frontmatter names
+// skillID.
+func findSkillMarkdown(ctx context.Context, provider previewProvider, all []string, skillID string) (string, string, error) {
+	for _, p := range rankSkillMdPaths(all, skillID) {
+		candidate, _, err := provider.fetchFile(ctx, p)
+		if errors.Is(err, ErrRateLimited) {
+			return "", "", err
+		}
+		if err != nil {
 			continue
 		}
 		name, ok := parseSkillNameFromFrontmatter(candidate)
 		if ok && name == skillID {
-			return candidate, nil
+			return p, candidate, nil
 		}
 	}
+	return "", "", ErrPreviewUnavailable
+}
+
+func (c Client) previewHTTP() *http.Client {
+	if c.HTTP == nil {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	return c.HTTP
+}
+
+// previewProvider picks the provider for source, if its host is supported.
+func (c Client) previewProvider(source string, httpClient *http.Client) (previewProvider, bool) {
+	if host, owner, repo, ok := parseGitHubRepoOn(source, c.GitHubHosts); ok {
+		rawBase, apiBase, token := c.githubEndpoints(host)
+		return githubPreview{http: httpClient, token: token, rawBase: rawBase, apiBase: apiBase, owner: owner, repo: repo}, true
+	}
+	if project, ok := parseGitLabProject(source); ok {
+		return gitlabPreview{http: httpClient, base: defaultBase(c.GitLabBase, "https://gitlab.com"), project: project}, true
+	}
+	if owner, repo, ok := parseBitbucketRepo(source); ok {
+		return &bitbucketPreview{http: httpClient, apiBase: defaultBase(c.BitbucketAPIBase, "https://api.bitbucket.org"), owner: owner, repo: repo}, true
+	}
+	if host, owner, repo, ok := parseGiteaRepo(source); ok {
+		return giteaPreview{http: httpClient, base: "https://" + host, owner: owner, repo: repo}, true
+	}
+	return nil, false
+}
+
+type githubPreview struct {
+	http             *http.Client
+	token            string
+	rawBase, apiBase string
+	owner, repo      string
+}
 
-	return "", ErrPreviewUnavailable
+func (g githubPreview) fetchFile(ctx context.Context, relPath string) (string, int, error) {
+	return fetchGitHubRaw(ctx, g.http, g.token, g.rawBase, g.owner, g.repo, relPath)
 }
 
-func fetchGitHubRaw(ctx context.Context, httpClient *http.Client, rawBase, owner, repo, relPath string) (string, int, error) {
+func (g githubPreview) listFiles(ctx context.Context) ([]string, error) {
+	return fetchGitHubTree(ctx, g.http, g.token, g.apiBase, g.owner, g.repo)
+}
+
+func fetchGitHubRaw(ctx context.Context, httpClient *http.Client, token, rawBase, owner, repo, relPath string) (string, int, error) {
 	u, err := url.Parse(rawBase)
 	if err != nil {
 		return "", 0, fmt.Errorf("%w: %w", ErrPreviewUnavailable, err)
@@ -100,29 +162,44 @@ func fetchGitHubRaw(ctx context.Context, httpClient *http.Client, rawBase, owner
 
 	u.Path = path.Join(u.Path, owner, repo, "HEAD", relPath)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	req, err := newGitHubRequest(ctx, u.String(), token)
 	if err != nil {
 		return "", 0, fmt.Errorf("%w: %w", ErrPreviewUnavailable, err)
 	}
+	return doPreviewRequest(httpClient, req)
+}
 
+// doPreviewRequest sends req and returns the body of a 2xx response. Errors
+// wrap ErrPreviewUnavailable, except rate limits, which are a
+// *RateLimitError.
+func doPreviewRequest(httpClient *http.Client, req *http.Request) (string, int, error) {
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		return "", 0, fmt.Errorf("%w: %w", ErrPreviewUnavailable, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if err := rateLimitError(resp); err != nil {
+		return "", resp.StatusCode, err
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return "", resp.StatusCode, fmt.Errorf("%w: %s", ErrPreviewUnavailable, resp.Status)
 	}
 
-	b, err := io.ReadAll(resp.Body)
+	if resp.ContentLength > MaxPreviewFileSize {
+		return "", resp.StatusCode, fmt.Errorf("file too large to preview (%d KB)", resp.ContentLength>>10)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, MaxPreviewFileSize+1))
 	if err != nil {
 		return "", resp.StatusCode, fmt.Errorf("%w: %w", ErrPreviewUnavailable, err)
 	}
+	if len(b) > MaxPreviewFileSize {
+		return "", resp.StatusCode, fmt.Errorf("file too large to preview (over %d KB)", MaxPreviewFileSize>>10)
+	}
 	return string(b), resp.StatusCode, nil
 }
 
-func fetchGitHubTreeSkillMdPaths(ctx context.Context, httpClient *http.Client, apiBase, owner, repo, skillID string) ([]string, error) {
+func fetchGitHubTree(ctx context.Context, httpClient *http.Client, token, apiBase, owner, repo string) ([]string, error) {
 	u, err := url.Parse(apiBase)
 	if err != nil {
 		return nil, err
@@ -132,7 +209,7 @@ func fetchGitHubTreeSkillMdPaths(ctx context.Context, httpClient *http.Client, a
 	q.Set("recursive", "1")
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	req, err := newGitHubRequest(ctx, u.String(), token)
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +220,9 @@ func fetchGitHubTreeSkillMdPaths(ctx context.Context, httpClient *http.Client, a
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if err := rateLimitError(resp); err != nil {
+		return nil, err
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("tree listing failed: %s", resp.Status)
 	}
@@ -152,15 +232,25 @@ func fetchGitHubTreeSkillMdPaths(ctx context.Context, httpClient *http.Client, a
 		return nil, err
 	}
 
-	all := make([]string, 0, 64)
+	all := make([]string, 0, len(decoded.Tree))
 	for _, it := range decoded.Tree {
-		if it.Type != "blob" {
-			continue
-		}
-		if strings.EqualFold(path.Base(it.Path), "SKILL.md") {
+		if it.Type == "blob" {
 			all = append(all, it.Path)
 		}
 	}
+	return all, nil
+}
+
+// rankSkillMdPaths picks the SKILL.md files out of a repository listing:
+// paths ending in <skillID>/SKILL.md first, then the priority skill
+// directories, then everything else, capped at maxPreviewCandidates.
+func rankSkillMdPaths(files []string, skillID string) []string {
+	all := make([]string, 0, 64)
+	for _, p := range files {
+		if strings.EqualFold(path.Base(p), "SKILL.md") {
+			all = append(all, p)
+		}
+	}
 
 	skillID = strings.TrimSpace(skillID)
 	exact := make([]string, 0, len(all))
@@ -191,58 +281,63 @@ func fetchGitHubTreeSkillMdPaths(ctx context.Context, httpClient *http.Client, a
 	if len(combined) > maxPreviewCandidates {
 		combined = combined[:maxPreviewCandidates]
 	}
-	return combined, nil
+	return combined
 }
 
 func parseGitHubRepo(source string) (owner, repo string, ok bool) {
+	_, owner, repo, ok = parseGitHubRepoOn(source, nil)
+	return owner, repo, ok
+}
+
+// parseGitHubRepoOn is parseGitHubRepo that also recognizes the enterprise
+// hosts, returning which host matched ("github.com" for github.com itself).
+// Besides URLs and git@host:owner/repo, enterprise repos can be written as
+// host/owner/repo.
+func parseGitHubRepoOn(source string, hosts []GitHubHost) (host, owner, repo string, ok bool) {
 	s := strings.TrimSpace(source)
 	if s == "" {
-		return "", "", false
+		return "", "", "", false
 	}
 
 	if shorthandRepoRe.MatchString(s) {
 		parts := strings.SplitN(s, "/", 2)
-		return parts[0], parts[1], true
+		return "github.com", parts[0], parts[1], true
 	}
 
-	if strings.HasPrefix(s, "git@github.com:") {
-		p := strings.TrimPrefix(s, "git@github.com:")
-		p = strings.TrimSuffix(p, ".git")
-		p = strings.Trim(p, "/")
-		parts := strings.Split(p, "/")
-		if len(parts) >= 2 && parts[0] != "" && parts[1] != "" {
-			return parts[0], parts[1], true
+	var p string
+	switch {
+	case strings.HasPrefix(s, "git@"):
+		h, rest, found := strings.Cut(strings.TrimPrefix(s, "git@"), ":")
+		if !found {
+			return "", "", "", false
 		}
-		return "", "", false
-	}
-
-	if strings.HasPrefix(s, "github.com/") {
-		s = "https://" + s
+		host, p = strings.ToLower(h), rest
+	default:
+		if !strings.Contains(s, "://") {
+			s = "https://" + s
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", "", "", false
+		}
+		host, p = strings.ToLower(u.Host), u.Path
 	}
 
-	u, err := url.Parse(s)
-	if err != nil {
-		return "", "", false
+	if host == "www.github.com" {
+		host = "github.com"
 	}
-
-	host := strings.ToLower(u.Host)
-	if host != "github.com" && host != "www.github.com" {
-		return "", "", false
+	if host != "github.com" && !hasGitHubHost(hosts, host) {
+		return "", "", "", false
 	}
 
-	p := strings.Trim(u.Path, "/")
-	if p == "" {
-		return "", "", false
-	}
-	parts := strings.Split(p, "/")
+	parts := strings.Split(strings.Trim(p, "/"), "/")
 	if len(parts) < 2 {
-		return "", "", false
+		return "", "", "", false
 	}
-
 	owner = parts[0]
 	repo = strings.TrimSuffix(parts[1], ".git")
 	if owner == "" || repo == "" {
-		return "", "", false
+		return "", "", "", false
 	}
-	return owner, repo, true
+	return host, owner, repo, true
 }
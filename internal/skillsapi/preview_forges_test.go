@@ -0,0 +1,99 @@
+package skillsapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const forgeSkillMd = "---\nname: lint\ndescription: x\n---\n# Lint\n"
+
+func TestClient_FetchSkillMarkdown_GitLab(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const project = "/api/v4/projects/group%2Fsub%2Frepo"
+		switch {
+		case r.URL.EscapedPath() == project+"/repository/tree" && r.URL.Query().Get("page") == "1":
+			w.Header().Set("X-Next-Page", "2")
+			_, _ = w.Write([]byte(`[{"path":"README.md","type":"blob"}]`))
+		case r.URL.EscapedPath() == project+"/repository/tree" && r.URL.Query().Get("page") == "2":
+			_, _ = w.Write([]byte(`[{"path":"tools","type":"tree"},{"path":"tools/lint/SKILL.md","type":"blob"}]`))
+		case r.URL.EscapedPath() == project+"/repository/files/tools%2Flint%2FSKILL.md/raw":
+			_, _ = w.Write([]byte(forgeSkillMd))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := Client{GitLabBase: srv.URL, HTTP: &http.Client{Timeout: 2 * time.Second}}
+	md, err := c.FetchSkillMarkdown(context.Background(), Skill{SkillID: "lint", Source: "gitlab:group/sub/repo"})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if md != forgeSkillMd {
+		t.Fatalf("unexpected md: %q", md)
+	}
+}
+
+func TestClient_FetchSkillMarkdown_Bitbucket(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2.0/repositories/acme/skills":
+			_, _ = w.Write([]byte(`{"mainbranch":{"name":"trunk"}}`))
+		case "/2.0/repositories/acme/skills/src/trunk/":
+			_, _ = w.Write([]byte(`{"values":[{"path":"README.md","type":"commit_file"}],"next":"` + srv.URL + `/page2"}`))
+		case "/page2":
+			_, _ = w.Write([]byte(`{"values":[{"path":".agents/skills/lint/SKILL.md","type":"commit_file"}]}`))
+		case "/2.0/repositories/acme/skills/src/trunk/.agents/skills/lint/SKILL.md":
+			_, _ = w.Write([]byte(forgeSkillMd))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := Client{BitbucketAPIBase: srv.URL, HTTP: &http.Client{Timeout: 2 * time.Second}}
+	for _, source := range []string{"bitbucket:acme/skills", "https://bitbucket.org/acme/skills.git"} {
+		md, err := c.FetchSkillMarkdown(context.Background(), Skill{SkillID: "lint", Source: source})
+		if err != nil {
+			t.Fatalf("%s: expected nil err, got %v", source, err)
+		}
+		if md != forgeSkillMd {
+			t.Fatalf("%s: unexpected md: %q", source, md)
+		}
+	}
+}
+
+func TestClient_FetchSkillMarkdown_Gitea(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/acme/skills":
+			_, _ = w.Write([]byte(`{"default_branch":"main"}`))
+		case "/api/v1/repos/acme/skills/git/trees/main":
+			if r.URL.Query().Get("page") == "1" {
+				_, _ = w.Write([]byte(`{"tree":[{"path":"README.md","type":"blob"}],"truncated":true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"tree":[{"path":"lint/SKILL.md","type":"blob"}],"truncated":false}`))
+		case "/api/v1/repos/acme/skills/raw/lint/SKILL.md":
+			_, _ = w.Write([]byte(forgeSkillMd))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+	c := Client{HTTP: srv.Client()}
+	md, err := c.FetchSkillMarkdown(context.Background(), Skill{SkillID: "lint", Source: "gitea:" + host + "/acme/skills"})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if md != forgeSkillMd {
+		t.Fatalf("unexpected md: %q", md)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -80,3 +82,108 @@ func TestClient_FetchSkillMarkdown_UnsupportedSource(t *testing.T) {
 		t.Fatalf("expected ErrPreviewUnavailable, got %v", err)
 	}
 }
+
+func TestClient_FetchSkillMarkdown_SendsTokenToGitHubOnly(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("# Private\n"))
+	}))
+	defer srv.Close()
+
+	c := Client{GitHubRawBase: srv.URL, GitHubToken: "s3cret", HTTP: &http.Client{Timeout: 2 * time.Second}}
+	md, err := c.FetchSkillMarkdown(context.Background(), Skill{SkillID: "x", Source: "acme/private"})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if md != "# Private\n" || gotAuth != "Bearer s3cret" {
+		t.Fatalf("md=%q auth=%q", md, gotAuth)
+	}
+
+	// Search goes to the registry, which must not see the token.
+	gotAuth = ""
+	c.BaseURL = srv.URL
+	_, _ = c.Search(context.Background(), "x", 1)
+	if gotAuth != "" {
+		t.Fatalf("token sent to registry: %q", gotAuth)
+	}
+}
+
+func TestClient_FetchSkillMarkdown_RateLimited(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := Client{GitHubRawBase: srv.URL, GitHubAPIBase: srv.URL, HTTP: &http.Client{Timeout: 2 * time.Second}}
+	_, err := c.FetchSkillMarkdown(context.Background(), Skill{SkillID: "x", Source: "acme/skills"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	var rl *RateLimitError
+	if !errors.As(err, &rl) || !rl.Reset.Equal(reset) || rl.Authenticated {
+		t.Fatalf("unexpected rate limit error: %#v", rl)
+	}
+	if !strings.Contains(err.Error(), "GITHUB_TOKEN") {
+		t.Fatalf("expected token hint, got %q", err.Error())
+	}
+}
+
+func TestParseGitHubRepoOn_EnterpriseHosts(t *testing.T) {
+	hosts := []GitHubHost{{Host: "github.acme.com"}}
+	tests := []struct {
+		source string
+		ok     bool
+		host   string
+	}{
+		{"obra/superpowers", true, "github.com"},
+		{"https://github.acme.com/team/skills", true, "github.acme.com"},
+		{"git@github.acme.com:team/skills.git", true, "github.acme.com"},
+		{"github.acme.com/team/skills", true, "github.acme.com"},
+		{"https://gitlab.acme.com/team/skills", false, ""},
+	}
+	for _, tt := range tests {
+		host, owner, repo, ok := parseGitHubRepoOn(tt.source, hosts)
+		if ok != tt.ok || host != tt.host {
+			t.Fatalf("%s: got host=%q ok=%v", tt.source, host, ok)
+		}
+		if ok && (owner == "" || repo == "" || strings.HasSuffix(repo, ".git")) {
+			t.Fatalf("%s: owner=%q repo=%q", tt.source, owner, repo)
+		}
+	}
+	if _, _, ok := parseGitHubRepo("https://github.acme.com/team/skills"); ok {
+		t.Fatalf("enterprise host recognized without configuration")
+	}
+}
+
+func TestClient_FetchSkillMarkdown_RoutesEnterpriseHost(t *testing.T) {
+	var gotPath, gotAuth string
+	ghe := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("# Internal\n"))
+	}))
+	defer ghe.Close()
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("public GitHub was asked for %s", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer public.Close()
+
+	c := Client{
+		GitHubRawBase: public.URL,
+		GitHubAPIBase: public.URL,
+		GitHubToken:   "public-token",
+		GitHubHosts:   []GitHubHost{{Host: "github.acme.com", RawBase: ghe.URL + "/raw", Token: "ghe-token"}},
+		HTTP:          &http.Client{Timeout: 2 * time.Second},
+	}
+	md, err := c.FetchSkillMarkdown(context.Background(), Skill{SkillID: "x", Source: "github.acme.com/team/skills"})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if md != "# Internal\n" || gotPath != "/raw/team/skills/HEAD/skills/x/SKILL.md" || gotAuth != "Bearer ghe-token" {
+		t.Fatalf("md=%q path=%q auth=%q", md, gotPath, gotAuth)
+	}
+}
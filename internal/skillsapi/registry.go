@@ -0,0 +1,176 @@
+package skillsapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry is a catalog of skills that can be searched, listed and previewed.
+// Client (skills.sh) is the default implementation.
+type Registry interface {
+	// Name labels results from this registry in the UI.
+	Name() string
+	Search(ctx context.Context, query string, limit int) ([]Skill, error)
+	Popular(ctx context.Context, limit int) ([]Skill, error)
+	FetchSkillMarkdown(ctx context.Context, skill Skill) (string, error)
+}
+
+// Name returns "skills.sh", or the host of BaseURL when it's overridden.
+func (c Client) Name() string {
+	if u, err := url.Parse(strings.TrimSpace(c.BaseURL)); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "skills.sh"
+}
+
+// Multi queries several registries and merges their results, taking one
+// from each registry in turn so a registry with many results can't crowd out
+// the others. Skills an earlier registry already returned are dropped. Each
+// result's Registry is set to the name of the registry it came from.
+type Multi []Registry
+
+func (m Multi) Name() string {
+	names := make([]string, 0, len(m))
+	for _, r := range m {
+		names = append(names, r.Name())
+	}
+	return strings.Join(names, ", ")
+}
+
+// Search fails only if every registry fails; otherwise the errors of the
+// failing ones are dropped so one unreachable catalog doesn't hide the rest.
+func (m Multi) Search(ctx context.Context, query string, limit int) ([]Skill, error) {
+	return m.gather(func(r Registry) ([]Skill, error) { return r.Search(ctx, query, limit) }, limit)
+}
+
+// Popular merges every registry's popular list, then sorts the merged list by
+// installs. Registries without install counts (such as indexes) still get
+// their share of the limit.
+func (m Multi) Popular(ctx context.Context, limit int) ([]Skill, error) {
+	skills, err := m.gather(func(r Registry) ([]Skill, error) { return r.Popular(ctx, limit) }, limit)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(skills, func(i, j int) bool {
+		return skills[i].Installs > skills[j].Installs
+	})
+	return skills, nil
+}
+
+// FetchSkillMarkdown asks the registry the skill came from, falling back to
+// the first registry for skills without a known registry.
+func (m Multi) FetchSkillMarkdown(ctx context.Context, skill Skill) (string, error) {
+	r := m.registryFor(skill)
+	if r == nil {
+		return "", ErrPreviewUnavailable
+	}
+	return r.FetchSkillMarkdown(ctx, skill)
+}
+
+// ListSkillFiles asks the registry the skill came from, if it can browse
+// files.
+func (m Multi) ListSkillFiles(ctx context.Context, skill Skill) (SkillFiles, error) {
+	fb, ok := m.registryFor(skill).(FileBrowser)
+	if !ok {
+		return SkillFiles{}, ErrPreviewUnavailable
+	}
+	return fb.ListSkillFiles(ctx, skill)
+}
+
+func (m Multi) FetchSkillFile(ctx context.Context, skill Skill, repoPath string) (string, error) {
+	fb, ok := m.registryFor(skill).(FileBrowser)
+	if !ok {
+		return "", ErrPreviewUnavailable
+	}
+	return fb.FetchSkillFile(ctx, skill, repoPath)
+}
+
+// registryFor returns the registry skill came from, or the first one for
+// skills without a known registry.
+func (m Multi) registryFor(skill Skill) Registry {
+	if len(m) == 0 {
+		return nil
+	}
+	for _, r := range m {
+		if r.Name() == skill.Registry {
+			return r
+		}
+	}
+	return m[0]
+}
+
+func (m Multi) gather(fn func(Registry) ([]Skill, error), limit int) ([]Skill, error) {
+	results := make([][]Skill, len(m))
+	errs := make([]error, len(m))
+
+	var wg sync.WaitGroup
+	for i, r := range m {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = fn(r)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if len(m) > 0 && failed == len(m) {
+		return nil, errors.Join(errs...)
+	}
+
+	seen := map[string]bool{}
+	lists := make([][]Skill, len(results))
+	for i, skills := range results {
+		name := m[i].Name()
+		for _, s := range skills {
+			key := dedupeKey(s)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if s.Registry == "" {
+				s.Registry = name
+			}
+			lists[i] = append(lists[i], s)
+		}
+	}
+	return interleave(lists, limit), nil
+}
+
+// interleave takes one skill from each list in turn, up to limit (if set).
+func interleave(lists [][]Skill, limit int) []Skill {
+	var out []Skill
+	for depth := 0; ; depth++ {
+		added := false
+		for _, l := range lists {
+			if depth >= len(l) {
+				continue
+			}
+			out = append(out, l[depth])
+			added = true
+			if limit > 0 && len(out) == limit {
+				return out
+			}
+		}
+		if !added {
+			return out
+		}
+	}
+}
+
+func dedupeKey(s Skill) string {
+	source := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s.Source), ".git"))
+	if owner, repo, ok := parseGitHubRepo(source); ok {
+		source = owner + "/" + repo
+	}
+	return source + "|" + strings.ToLower(strings.TrimSpace(s.SkillID))
+}
@@ -0,0 +1,251 @@
+package skillsapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeRegistry struct {
+	name   string
+	skills []Skill
+	err    error
+	md     string
+}
+
+func (f fakeRegistry) Name() string { return f.name }
+func (f fakeRegistry) Search(context.Context, string, int) ([]Skill, error) {
+	return f.skills, f.err
+}
+func (f fakeRegistry) Popular(context.Context, int) ([]Skill, error) {
+	return f.skills, f.err
+}
+func (f fakeRegistry) FetchSkillMarkdown(context.Context, Skill) (string, error) {
+	return f.md, nil
+}
+
+func TestMulti_MergesAndDedupesWithRegistryLabels(t *testing.T) {
+	m := Multi{
+		fakeRegistry{name: "skills.sh", skills: []Skill{
+			{SkillID: "a", Source: "owner/repo", Installs: 5},
+		}},
+		fakeRegistry{name: "internal", skills: []Skill{
+			{SkillID: "a", Source: "https://github.com/owner/repo.git", Installs: 1},
+			{SkillID: "b", Source: "team/skills", Installs: 9},
+		}},
+		fakeRegistry{name: "down", err: errors.New("boom")},
+	}
+
+	skills, err := m.Search(context.Background(), "x", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 2 {
+		t.Fatalf("expected 2 merged skills, got %#v", skills)
+	}
+	if skills[0].SkillID != "a" || skills[0].Registry != "skills.sh" {
+		t.Fatalf("expected first registry to win duplicates, got %#v", skills[0])
+	}
+	if skills[1].SkillID != "b" || skills[1].Registry != "internal" {
+		t.Fatalf("unexpected second skill: %#v", skills[1])
+	}
+
+	popular, err := m.Popular(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(popular) != 2 || popular[0].SkillID != "b" {
+		t.Fatalf("expected most installed skill first, got %#v", popular)
+	}
+}
+
+func TestMulti_InterleavesSoEveryRegistryGetsAShare(t *testing.T) {
+	var big []Skill
+	for _, id := range []string{"s1", "s2", "s3", "s4"} {
+		big = append(big, Skill{SkillID: id, Source: "owner/repo", Installs: 100})
+	}
+	m := Multi{
+		fakeRegistry{name: "skills.sh", skills: big},
+		fakeRegistry{name: "internal", skills: []Skill{
+			{SkillID: "i1", Source: "team/skills"},
+			{SkillID: "i2", Source: "team/skills"},
+		}},
+	}
+
+	ids := func(skills []Skill) string {
+		var out []string
+		for _, s := range skills {
+			out = append(out, s.SkillID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	found, err := m.Search(context.Background(), "x", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(found); got != "s1,i1,s2,i2" {
+		t.Fatalf("search=%s", got)
+	}
+
+	// Index entries have no installs, so they sort last, but still make the cut.
+	popular, err := m.Popular(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(popular); got != "s1,s2,i1,i2" {
+		t.Fatalf("popular=%s", got)
+	}
+}
+
+func TestMulti_FailsWhenEveryRegistryFails(t *testing.T) {
+	m := Multi{fakeRegistry{name: "a", err: errors.New("a down")}, fakeRegistry{name: "b", err: errors.New("b down")}}
+	if _, err := m.Search(context.Background(), "x", 10); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestMulti_PreviewUsesSkillRegistry(t *testing.T) {
+	m := Multi{fakeRegistry{name: "a", md: "from a"}, fakeRegistry{name: "b", md: "from b"}}
+	md, err := m.FetchSkillMarkdown(context.Background(), Skill{Registry: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md != "from b" {
+		t.Fatalf("md=%q", md)
+	}
+}
+
+func TestCatalog_PopularAndSearch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/popular":
+			if r.URL.Query().Get("limit") != "5" {
+				t.Errorf("limit=%q", r.URL.Query().Get("limit"))
+			}
+			_, _ = w.Write([]byte(`{"skills":[{"skillId":"p","source":"team/skills"}]}`))
+		case "/api/search":
+			_, _ = w.Write([]byte(`{"skills":[{"skillId":"s","source":"team/skills"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := Catalog{Label: "internal", BaseURL: srv.URL, HTTP: srv.Client()}
+	if c.Name() != "internal" {
+		t.Fatalf("name=%q", c.Name())
+	}
+	popular, err := c.Popular(context.Background(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(popular) != 1 || popular[0].SkillID != "p" {
+		t.Fatalf("popular=%#v", popular)
+	}
+	found, err := c.Search(context.Background(), "s", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].SkillID != "s" {
+		t.Fatalf("search=%#v", found)
+	}
+}
+
+func TestIndex_SearchRanksLocally(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	body := `{"skills":[
+		{"skillId":"git-helpers","name":"git-helpers","source":"team/skills","installs":1},
+		{"skillId":"commit","name":"commit","source":"team/git-tools","installs":3},
+		{"skillId":"git","name":"git","source":"team/skills"},
+		{"skillId":"docs","name":"docs","source":"team/skills"}
+	]}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	x := &Index{Location: path}
+	found, err := x.Search(context.Background(), "git", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range found {
+		ids = append(ids, s.SkillID)
+	}
+	want := []string{"git", "git-helpers", "commit"}
+	if len(ids) != len(want) {
+		t.Fatalf("ids=%v", ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids=%v want %v", ids, want)
+		}
+	}
+
+	popular, err := x.Popular(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(popular) != 1 || popular[0].SkillID != "commit" {
+		t.Fatalf("popular=%#v", popular)
+	}
+}
+
+func TestParseIndex_RejectsNewerVersion(t *testing.T) {
+	if _, err := ParseIndex([]byte(`{"version": 99, "skills": []}`)); err == nil {
+		t.Fatalf("expected error for newer index version")
+	}
+	f, err := ParseIndex([]byte(`{"skills": [{"skillId": "a"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Version != IndexVersion || len(f.Skills) != 1 {
+		t.Fatalf("index=%+v", f)
+	}
+}
+
+func TestIndex_RetriesAFailedLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	x := &Index{Location: path}
+	if _, err := x.Search(context.Background(), "git", 10); err == nil {
+		t.Fatal("expected missing index to fail")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"skills":[{"skillId":"git","source":"team/skills"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	found, err := x.Search(context.Background(), "git", 10)
+	if err != nil || len(found) != 1 {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+
+	// Once loaded, the index isn't read again.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := x.Search(context.Background(), "git", 10); err != nil || len(found) != 1 {
+		t.Fatalf("found=%v err=%v", found, err)
+	}
+}
+
+func TestIndex_PreviewFallsBackToDescription(t *testing.T) {
+	x := &Index{}
+	md, err := x.FetchSkillMarkdown(context.Background(), Skill{
+		SkillID:     "alpha",
+		Description: "Internal skill",
+		Source:      "https://git.internal.example/team/skills.git",
+		Tags:        []string{"git"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md, "# alpha") || !strings.Contains(md, "Internal skill") || !strings.Contains(md, "Tags: git") {
+		t.Fatalf("md=%q", md)
+	}
+}
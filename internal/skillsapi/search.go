@@ -17,6 +17,16 @@ type Skill struct {
 	Name     string `json:"name"`
 	Installs int    `json:"installs"`
 	Source   string `json:"source"`
+
+	// Description, Ref, Path and Tags come from static indexes (see
+	// IndexFile); skills.sh leaves them empty.
+	Description string   `json:"description,omitempty"`
+	Ref         string   `json:"ref,omitempty"`
+	Path        string   `json:"path,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	// Registry names the registry the skill was found in (set by Multi).
+	Registry string `json:"registry,omitempty"`
 }
 
 type Client struct {
@@ -24,6 +34,18 @@ type Client struct {
 	GitHubRawBase string
 	GitHubAPIBase string
 	HTTP          *http.Client
+
+	// GitHubToken, if set, is sent to GitHubRawBase and GitHubAPIBase (and
+	// nowhere else) for previews of private repos and a higher rate limit.
+	GitHubToken string
+
+	// GitHubHosts are GitHub Enterprise instances previews can fetch from.
+	GitHubHosts []GitHubHost
+
+	// GitLabBase and BitbucketAPIBase override https://gitlab.com and
+	// https://api.bitbucket.org for previews.
+	GitLabBase       string
+	BitbucketAPIBase string
 }
 
 type searchResponse struct {
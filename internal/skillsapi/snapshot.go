@@ -0,0 +1,225 @@
+package skillsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the current catalog snapshot format version.
+const SnapshotVersion = 1
+
+// Snapshot is an offline copy of registry metadata, written by
+// `skulls catalog pull`.
+type Snapshot struct {
+	Version  int             `json:"version"`
+	PulledAt time.Time       `json:"pulled_at"`
+	Skills   []SnapshotSkill `json:"skills"`
+}
+
+// SnapshotSkill is a skill plus, optionally, its SKILL.md body.
+type SnapshotSkill struct {
+	Skill
+	Body string `json:"body,omitempty"`
+}
+
+// PullOptions controls PullSnapshot.
+type PullOptions struct {
+	// Limit caps the popular list requested from the registry.
+	Limit int
+	// Queries are searched in addition to the popular list, to pull in skills
+	// that aren't popular.
+	Queries []string
+	// Bodies also fetches each skill's SKILL.md for full-text search.
+	Bodies bool
+	// Progress, if set, is called after each body fetch.
+	Progress func(done int, total int)
+}
+
+// PullSnapshot collects popular skills and the results of opts.Queries from
+// reg. Failed body fetches are skipped; the skill is kept without a body.
+func PullSnapshot(ctx context.Context, reg Registry, opts PullOptions) (Snapshot, error) {
+	snap := Snapshot{Version: SnapshotVersion, PulledAt: time.Now().UTC()}
+
+	popular, err := reg.Popular(ctx, opts.Limit)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("popular: %w", err)
+	}
+	seen := map[string]bool{}
+	add := func(skills []Skill) {
+		for _, s := range skills {
+			key := dedupeKey(s)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			snap.Skills = append(snap.Skills, SnapshotSkill{Skill: s})
+		}
+	}
+	add(popular)
+	for _, q := range opts.Queries {
+		found, err := reg.Search(ctx, q, opts.Limit)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("search %q: %w", q, err)
+		}
+		add(found)
+	}
+
+	if opts.Bodies {
+		for i := range snap.Skills {
+			if md, err := reg.FetchSkillMarkdown(ctx, snap.Skills[i].Skill); err == nil {
+				snap.Skills[i].Body = md
+			}
+			if ctx.Err() != nil {
+				return Snapshot{}, ctx.Err()
+			}
+			if opts.Progress != nil {
+				opts.Progress(i+1, len(snap.Skills))
+			}
+		}
+	}
+	return snap, nil
+}
+
+// WriteSnapshot saves snap to path, creating its directory.
+func WriteSnapshot(path string, snap Snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadSnapshot loads a snapshot written by WriteSnapshot.
+func ReadSnapshot(path string) (Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	if snap.Version > SnapshotVersion {
+		return Snapshot{}, fmt.Errorf("snapshot version %d is newer than supported version %d; pull it again", snap.Version, SnapshotVersion)
+	}
+	return snap, nil
+}
+
+// Name labels the snapshot as offline.
+func (s Snapshot) Name() string { return "offline" }
+
+// Search ranks skills locally by name, description, tags and body text. Every
+// query term has to match somewhere.
+func (s Snapshot) Search(_ context.Context, query string, limit int) ([]Skill, error) {
+	terms := strings.Fields(strings.ToLower(query))
+
+	type scored struct {
+		s     Skill
+		score int
+	}
+	var hits []scored
+	for _, sk := range s.Skills {
+		if score := rankSnapshotSkill(sk, terms); score > 0 {
+			hits = append(hits, scored{sk.Skill, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].s.Installs > hits[j].s.Installs
+	})
+
+	out := make([]Skill, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.s)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// Popular returns the snapshot's skills, most installs first.
+func (s Snapshot) Popular(_ context.Context, limit int) ([]Skill, error) {
+	out := make([]Skill, 0, len(s.Skills))
+	for _, sk := range s.Skills {
+		out = append(out, sk.Skill)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Installs > out[j].Installs })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// FetchSkillMarkdown returns the stored body, or a summary built from the
+// metadata when bodies weren't pulled.
+func (s Snapshot) FetchSkillMarkdown(_ context.Context, skill Skill) (string, error) {
+	key := dedupeKey(skill)
+	for _, sk := range s.Skills {
+		if dedupeKey(sk.Skill) != key {
+			continue
+		}
+		if sk.Body != "" {
+			return sk.Body, nil
+		}
+		if sk.Description != "" {
+			return indexSummaryMarkdown(sk.Skill), nil
+		}
+		break
+	}
+	return "", fmt.Errorf("%w: not in offline snapshot (pull with --bodies)", ErrPreviewUnavailable)
+}
+
+// rankSnapshotSkill scores sk against lowercased terms; 0 means no match.
+func rankSnapshotSkill(sk SnapshotSkill, terms []string) int {
+	if len(terms) == 0 {
+		return 1
+	}
+	id := strings.ToLower(sk.SkillID)
+	name := strings.ToLower(sk.Name)
+	desc := strings.ToLower(sk.Description)
+	body := strings.ToLower(sk.Body)
+
+	total := 0
+	for _, t := range terms {
+		score := 0
+		switch {
+		case id == t || name == t:
+			score += 20
+		case strings.Contains(id, t) || strings.Contains(name, t):
+			score += 10
+		}
+		if hasTag(sk.Skill, t) {
+			score += 5
+		}
+		if strings.Contains(desc, t) {
+			score += 3
+		}
+		if n := strings.Count(body, t); n > 0 {
+			score += min(n, 5)
+		}
+		if strings.Contains(strings.ToLower(sk.Source), t) {
+			score++
+		}
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total
+}
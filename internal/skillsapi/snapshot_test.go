@@ -0,0 +1,85 @@
+package skillsapi
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestPullSnapshot_CollectsPopularQueriesAndBodies(t *testing.T) {
+	reg := fakeRegistry{name: "skills.sh", md: "# body", skills: []Skill{
+		{SkillID: "a", Source: "owner/repo", Installs: 2},
+		{SkillID: "b", Source: "owner/repo", Installs: 1},
+	}}
+
+	snap, err := PullSnapshot(context.Background(), reg, PullOptions{Limit: 10, Queries: []string{"x"}, Bodies: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Version != SnapshotVersion || snap.PulledAt.IsZero() {
+		t.Fatalf("unexpected header: %+v", snap)
+	}
+	if len(snap.Skills) != 2 {
+		t.Fatalf("expected duplicates from queries to be dropped, got %d skills", len(snap.Skills))
+	}
+	if snap.Skills[0].Body != "# body" {
+		t.Fatalf("body=%q", snap.Skills[0].Body)
+	}
+
+	path := filepath.Join(t.TempDir(), "nested", "catalog.json")
+	if err := WriteSnapshot(path, snap); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Skills) != 2 || !got.PulledAt.Equal(snap.PulledAt) {
+		t.Fatalf("round trip=%+v", got)
+	}
+}
+
+func TestSnapshot_SearchRanksNameDescriptionAndBody(t *testing.T) {
+	snap := Snapshot{Skills: []SnapshotSkill{
+		{Skill: Skill{SkillID: "notes", Source: "o/r", Installs: 100}, Body: "mentions terraform once"},
+		{Skill: Skill{SkillID: "infra", Source: "o/r", Description: "Terraform plans"}},
+		{Skill: Skill{SkillID: "terraform", Source: "o/r"}},
+		{Skill: Skill{SkillID: "docs", Source: "o/r"}, Body: "nothing relevant"},
+	}}
+
+	found, err := snap.Search(context.Background(), "Terraform", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range found {
+		ids = append(ids, s.SkillID)
+	}
+	want := []string{"terraform", "infra", "notes"}
+	if len(ids) != len(want) {
+		t.Fatalf("ids=%v", ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids=%v want %v", ids, want)
+		}
+	}
+
+	if found, _ := snap.Search(context.Background(), "terraform missing", 10); len(found) != 0 {
+		t.Fatalf("expected all terms to be required, got %v", found)
+	}
+}
+
+func TestSnapshot_PreviewUsesStoredBody(t *testing.T) {
+	snap := Snapshot{Skills: []SnapshotSkill{
+		{Skill: Skill{SkillID: "a", Source: "owner/repo"}, Body: "# stored"},
+		{Skill: Skill{SkillID: "b", Source: "owner/repo"}},
+	}}
+	md, err := snap.FetchSkillMarkdown(context.Background(), Skill{SkillID: "a", Source: "owner/repo"})
+	if err != nil || md != "# stored" {
+		t.Fatalf("md=%q err=%v", md, err)
+	}
+	if _, err := snap.FetchSkillMarkdown(context.Background(), Skill{SkillID: "b", Source: "owner/repo"}); err == nil {
+		t.Fatalf("expected preview unavailable without a body")
+	}
+}
@@ -0,0 +1,119 @@
+package textdiff
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Status is how a file differs between two directories.
+type Status string
+
+const (
+	StatusModified Status = "M"
+	StatusAdded    Status = "A"
+	StatusDeleted  Status = "D"
+)
+
+// FileDiff is the unified diff for one file.
+type FileDiff struct {
+	Path    string
+	Status  Status
+	Unified string
+}
+
+// Dirs diffs every regular file under oldDir against newDir. Files are
+// reported by slash-separated relative path, sorted, and unchanged files are
+// omitted.
+func Dirs(oldDir string, newDir string) ([]FileDiff, error) {
+	oldFiles, err := listFiles(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := listFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(oldFiles)+len(newFiles))
+	for p := range oldFiles {
+		paths = append(paths, p)
+	}
+	for p := range newFiles {
+		if _, ok := oldFiles[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	out := make([]FileDiff, 0, len(paths))
+	for _, p := range paths {
+		_, inOld := oldFiles[p]
+		_, inNew := newFiles[p]
+
+		var a, b []byte
+		if inOld {
+			if a, err = os.ReadFile(filepath.Join(oldDir, filepath.FromSlash(p))); err != nil {
+				return nil, err
+			}
+		}
+		if inNew {
+			if b, err = os.ReadFile(filepath.Join(newDir, filepath.FromSlash(p))); err != nil {
+				return nil, err
+			}
+		}
+		if inOld && inNew && bytes.Equal(a, b) {
+			continue
+		}
+
+		d := FileDiff{Path: p, Status: StatusModified}
+		aName, bName := "a/"+p, "b/"+p
+		switch {
+		case !inOld:
+			d.Status = StatusAdded
+			aName = "/dev/null"
+		case !inNew:
+			d.Status = StatusDeleted
+			bName = "/dev/null"
+		}
+		if isBinary(a) || isBinary(b) {
+			d.Unified = "Binary files " + aName + " and " + bName + " differ\n"
+		} else {
+			d.Unified = Unified(aName, bName, string(a), string(b), DefaultContext)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func listFiles(dir string) (map[string]struct{}, error) {
+	out := map[string]struct{}{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		out[filepath.ToSlash(rel)] = struct{}{}
+		return nil
+	})
+	return out, err
+}
+
+func isBinary(b []byte) bool {
+	n := len(b)
+	if n > 8000 {
+		n = 8000
+	}
+	return bytes.IndexByte(b[:n], 0) >= 0
+}
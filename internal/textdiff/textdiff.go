@@ -0,0 +1,251 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change.
+const DefaultContext = 3
+
+// maxCells bounds the state kept to backtrack a diff (see diffMiddle);
+// files with more changes than that are diffed as a whole-file replacement
+// rather than burning memory on a minimal diff.
+const maxCells = 16_000_000
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is one edit step. a and b are the positions in each input when the step
+// applies, so inserts still know where they sit in a (and deletes in b).
+type op struct {
+	kind opKind
+	a, b int
+}
+
+// Unified returns a unified diff of a and b, or "" if they're equal.
+func Unified(aName string, bName string, a string, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	al := splitLines(a)
+	bl := splitLines(b)
+	ops := diffLines(al, bl)
+
+	out := strings.Builder{}
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+	for _, h := range hunks(ops, context) {
+		writeHunk(&out, h, al, bl)
+	}
+	return out.String()
+}
+
+// splitLines splits s into lines, keeping the trailing "\n" on each line so
+// a missing newline at EOF shows up as a change.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func diffLines(a []string, b []string) []op {
+	// Trim common prefix/suffix to keep the table small.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for i := 0; i < pre; i++ {
+		ops = append(ops, op{kind: opEqual, a: i, b: i})
+	}
+	ops = append(ops, diffMiddle(a[pre:len(a)-suf], b[pre:len(b)-suf], pre)...)
+	for i := 0; i < suf; i++ {
+		ops = append(ops, op{kind: opEqual, a: len(a) - suf + i, b: len(b) - suf + i})
+	}
+	return ops
+}
+
+// diffMiddle finds a shortest edit script with Myers' algorithm. It takes
+// O((n+m)·D) time and keeps O(D²) state for the backtrack, D being the
+// number of changed lines, so large files with small changes stay cheap.
+func diffMiddle(a []string, b []string, offset int) []op {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b, offset)
+	}
+
+	// v[k+limit] is the furthest x reached on diagonal k = x-y. trace[d] is v
+	// for diagonals -(d-1)..d-1 as round d started.
+	limit := n + m
+	v := make([]int, 2*limit+2)
+	var trace [][]int32
+	cells := 0
+	d := 0
+search:
+	for ; d <= limit; d++ {
+		if d > 0 {
+			snap := make([]int32, 2*d-1)
+			for k := -(d - 1); k <= d-1; k++ {
+				snap[k+d-1] = int32(v[k+limit])
+			}
+			trace = append(trace, snap)
+			if cells += len(snap); cells > maxCells {
+				return replaceAll(a, b, offset)
+			}
+		} else {
+			trace = append(trace, nil)
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+limit] < v[k+1+limit]) {
+				x = v[k+1+limit]
+			} else {
+				x = v[k-1+limit] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+limit] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from (n, m), collecting ops in reverse.
+	ops := make([]op, 0, n+m)
+	x, y := n, m
+	for ; d > 0; d-- {
+		snap := trace[d]
+		prev := func(k int) int { return int(snap[k+d-1]) }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev(k-1) < prev(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{kind: opEqual, a: offset + x, b: offset + y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, op{kind: opInsert, a: offset + x, b: offset + y})
+		} else {
+			x--
+			ops = append(ops, op{kind: opDelete, a: offset + x, b: offset + y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, op{kind: opEqual, a: offset + x, b: offset + y})
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// replaceAll is the edit script deleting all of a and inserting all of b.
+func replaceAll(a []string, b []string, offset int) []op {
+	n := len(a)
+	ops := make([]op, 0, n+len(b))
+	for i := range a {
+		ops = append(ops, op{kind: opDelete, a: offset + i, b: offset})
+	}
+	for j := range b {
+		ops = append(ops, op{kind: opInsert, a: offset + n, b: offset + j})
+	}
+	return ops
+}
+
+// hunks groups ops into ranges of changes plus surrounding context.
+func hunks(ops []op, context int) [][]op {
+	var out [][]op
+	start := -1
+	lastChange := -1
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+		// Like diff -u, changes at most 2*context lines apart share a hunk.
+		if start >= 0 && i-lastChange-1 > 2*context {
+			out = append(out, ops[start:min(len(ops), lastChange+context+1)])
+			start = -1
+		}
+		if start < 0 {
+			start = max(0, i-context)
+		}
+		lastChange = i
+	}
+	if start >= 0 {
+		out = append(out, ops[start:min(len(ops), lastChange+context+1)])
+	}
+	return out
+}
+
+func writeHunk(out *strings.Builder, h []op, a []string, b []string) {
+	aCount, bCount := 0, 0
+	for _, o := range h {
+		switch o.kind {
+		case opEqual:
+			aCount++
+			bCount++
+		case opDelete:
+			aCount++
+		case opInsert:
+			bCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(h[0].a, aCount), hunkRange(h[0].b, bCount))
+
+	for _, o := range h {
+		switch o.kind {
+		case opEqual:
+			writeLine(out, " ", a[o.a])
+		case opDelete:
+			writeLine(out, "-", a[o.a])
+		case opInsert:
+			writeLine(out, "+", b[o.b])
+		}
+	}
+}
+
+// hunkRange formats a 1-based "start,count". Empty ranges point at the line
+// before the hunk, as in GNU diff.
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeLine(out *strings.Builder, prefix string, line string) {
+	out.WriteString(prefix)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,214 @@
+package textdiff
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnified_Equal(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n", 3); got != "" {
+		t.Fatalf("expected empty diff, got %q", got)
+	}
+}
+
+func TestUnified_ModifiedLine(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	b := "one\ntwo\nthree\nfour\nFIVE\nsix\nseven\neight\n"
+
+	want := `--- a/f
++++ b/f
+@@ -2,7 +2,7 @@
+ two
+ three
+ four
+-five
++FIVE
+ six
+ seven
+ eight
+`
+	if got := Unified("a/f", "b/f", a, b, 3); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_SeparateHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n"
+
+	want := `--- a
++++ b
+@@ -1,2 +1,2 @@
+-1
++one
+ 2
+@@ -9,2 +9,2 @@
+ 9
+-10
++ten
+`
+	if got := Unified("a", "b", a, b, 1); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_MergesHunksExactlyTwoContextsApart(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n"
+	b := "one\n2\n3\nfour\n5\n"
+
+	want := `--- a
++++ b
+@@ -1,5 +1,5 @@
+-1
++one
+ 2
+ 3
+-4
++four
+ 5
+`
+	if got := Unified("a", "b", a, b, 1); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_AddedFileAndMissingNewline(t *testing.T) {
+	want := `--- /dev/null
++++ b/f
+@@ -0,0 +1,2 @@
++hello
++world
+\ No newline at end of file
+`
+	if got := Unified("/dev/null", "b/f", "", "hello\nworld", 3); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDirs_ReportsStatuses(t *testing.T) {
+	oldDir := t.TempDir()
+	newDir := t.TempDir()
+	write := func(dir, rel, body string) {
+		t.Helper()
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(oldDir, "SKILL.md", "same\n")
+	write(newDir, "SKILL.md", "same\n")
+	write(oldDir, "changed.md", "old\n")
+	write(newDir, "changed.md", "new\n")
+	write(oldDir, "gone.md", "bye\n")
+	write(newDir, "sub/new.md", "hi\n")
+
+	diffs, err := Dirs(oldDir, newDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("got %d diffs: %+v", len(diffs), diffs)
+	}
+	want := []struct {
+		path   string
+		status Status
+	}{
+		{"changed.md", StatusModified},
+		{"gone.md", StatusDeleted},
+		{"sub/new.md", StatusAdded},
+	}
+	for i, w := range want {
+		if diffs[i].Path != w.path || diffs[i].Status != w.status {
+			t.Fatalf("diff %d = %s %s, want %s %s", i, diffs[i].Status, diffs[i].Path, w.status, w.path)
+		}
+		if diffs[i].Unified == "" {
+			t.Fatalf("diff %d has no body", i)
+		}
+	}
+}
+
+func TestDiffLines_IsAMinimalEditScript(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randLines := func() []string {
+		out := make([]string, rng.Intn(12))
+		for i := range out {
+			out[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return out
+	}
+	for range 2000 {
+		a, b := randLines(), randLines()
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+		edits := 0
+		for _, o := range ops {
+			switch o.kind {
+			case opEqual:
+				if a[o.a] != b[o.b] {
+					t.Fatalf("a=%v b=%v: unequal lines paired", a, b)
+				}
+				gotA, gotB = append(gotA, a[o.a]), append(gotB, b[o.b])
+			case opDelete:
+				gotA = append(gotA, a[o.a])
+				edits++
+			case opInsert:
+				gotB = append(gotB, b[o.b])
+				edits++
+			}
+		}
+		if fmt.Sprint(gotA) != fmt.Sprint(a) || fmt.Sprint(gotB) != fmt.Sprint(b) {
+			t.Fatalf("a=%v b=%v: ops don't cover both sides", a, b)
+		}
+		if want := len(a) + len(b) - 2*lcsLen(a, b); edits != want {
+			t.Fatalf("a=%v b=%v: %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func lcsLen(a []string, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return dp[0][0]
+}
+
+func TestUnified_LargeFilesWithSmallChanges(t *testing.T) {
+	var a, b strings.Builder
+	for i := range 50_000 {
+		fmt.Fprintf(&a, "line %d\n", i)
+		switch i {
+		case 10_000, 40_000:
+			fmt.Fprintf(&b, "changed %d\n", i)
+		default:
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+	}
+
+	start := time.Now()
+	got := Unified("a", "b", a.String(), b.String(), 0)
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("took %v", d)
+	}
+	if strings.Count(got, "@@ ") != 2 || !strings.Contains(got, "-line 10000\n+changed 10000\n") {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaofelix/skulls/internal/gitutil"
+	"github.com/kaofelix/skulls/internal/install"
+)
+
+// RunBatchInstall shows one progress row per item while install.InstallBatch
+// runs, in the normal terminal screen like RunInstall. It returns the batch's
+// results once every item has finished.
+//
+// opts.Progress and the git output writers are owned by the UI and overridden.
+// Ctrl+C cancels ctx for the batch and waits for it to clean up.
+func RunBatchInstall(ctx context.Context, items []install.BatchItem, opts install.BatchOptions) ([]install.BatchResult, error) {
+	m := newBatchModel(ctx, items, opts)
+	p := tea.NewProgram(m)
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	fm, ok := finalModel.(batchModel)
+	if !ok {
+		return nil, fmt.Errorf("unexpected model type %T", finalModel)
+	}
+	if fm.cancelling {
+		return fm.results, tea.ErrProgramKilled
+	}
+	return fm.results, nil
+}
+
+type batchEventMsg struct {
+	item  int
+	event install.Event
+}
+
+type batchDoneMsg []install.BatchResult
+
+type batchModel struct {
+	opts  install.BatchOptions
+	items []install.BatchItem
+
+	spin spinner.Model
+
+	// latest is the last event of each item; results is set once the
+	// batch is done.
+	latest  []*install.Event
+	results []install.BatchResult
+
+	msgCh      <-chan tea.Msg
+	cancel     context.CancelFunc
+	cancelling bool
+}
+
+func newBatchModel(ctx context.Context, items []install.BatchItem, opts install.BatchOptions) batchModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+
+	m := batchModel{
+		opts:   opts,
+		items:  items,
+		spin:   s,
+		latest: make([]*install.Event, len(items)),
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.msgCh = startBatchInstall(ctx, items, opts)
+	return m
+}
+
+func (m batchModel) Init() tea.Cmd {
+	return tea.Batch(m.spin.Tick, waitMsg(m.msgCh))
+}
+
+func (m batchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.cancelling = true
+			m.cancel()
+		}
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spin, cmd = m.spin.Update(msg)
+		return m, cmd
+	case batchEventMsg:
+		e := msg.event
+		m.latest[msg.item] = &e
+		return m, waitMsg(m.msgCh)
+	case batchDoneMsg:
+		m.cancel()
+		m.results = msg
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m batchModel) View() string {
+	banner := lipgloss.NewStyle().Bold(true)
+	muted := lipgloss.NewStyle().Faint(true)
+	ok := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	pending := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	bad := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	b := strings.Builder{}
+	b.WriteString(banner.Render(skullsBanner()))
+	b.WriteString("\n\n")
+	b.WriteString("Install dir: " + compactPath(m.opts.TargetDir) + "\n\n")
+
+	width := 0
+	for _, it := range m.items {
+		width = max(width, len(it.String()))
+	}
+
+	failed := 0
+	for i, it := range m.items {
+		name := fmt.Sprintf("%-*s", width, it.String())
+		if m.results != nil {
+			r := m.results[i]
+			if r.Err != nil {
+				failed++
+				b.WriteString(bad.Render("✗ "+name) + "  " + muted.Render(batchErrorLine(r.Err)) + "\n")
+			} else {
+				b.WriteString(ok.Render("◆ "+name) + "  " + muted.Render(compactPath(r.InstalledPath)) + "\n")
+			}
+			continue
+		}
+		e := m.latest[i]
+		if e == nil {
+			b.WriteString(muted.Render("○ "+name+"  Waiting") + "\n")
+			continue
+		}
+		status := stepLabel(e.Step)
+		if strings.TrimSpace(e.Message) != "" {
+			status = strings.TrimSpace(e.Message)
+		}
+		if e.Progress != nil && !e.Done {
+			status = transferLabel(*e.Progress)
+		}
+		b.WriteString(pending.Render(m.spin.View()+" "+name) + "  " + muted.Render(status) + "\n")
+	}
+
+	switch {
+	case m.results != nil:
+		b.WriteString("\n" + fmt.Sprintf("%d installed, %d failed", len(m.results)-failed, failed) + "\n")
+	case m.cancelling:
+		b.WriteString("\n" + muted.Render("Cancelling…") + "\n")
+	}
+	return b.String()
+}
+
+// batchErrorLine is err on a single row, with the hint for git failures.
+func batchErrorLine(err error) string {
+	var gitErr *gitutil.GitError
+	if errors.As(err, &gitErr) {
+		if hint := gitErr.Hint(); hint != "" {
+			return err.Error() + " (" + hint + ")"
+		}
+	}
+	return err.Error()
+}
+
+func startBatchInstall(ctx context.Context, items []install.BatchItem, opts install.BatchOptions) <-chan tea.Msg {
+	ch := make(chan tea.Msg, 128)
+	opts.GitStdout = io.Discard
+	opts.GitStderr = io.Discard
+	opts.Progress = func(i int, e install.Event) {
+		ch <- batchEventMsg{item: i, event: e}
+	}
+	go func() {
+		ch <- batchDoneMsg(install.InstallBatch(ctx, items, opts))
+		close(ch)
+	}()
+	return ch
+}
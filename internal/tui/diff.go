@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaofelix/skulls/internal/textdiff"
+)
+
+// RunDiff pages through per-file diffs: files on the left, the selected
+// file's diff on the right.
+func RunDiff(title string, diffs []textdiff.FileDiff) error {
+	p := tea.NewProgram(newDiffModel(title, diffs), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	_, err := p.Run()
+	return err
+}
+
+type diffItem struct {
+	d textdiff.FileDiff
+}
+
+func (i diffItem) Title() string { return i.d.Path }
+func (i diffItem) Description() string {
+	switch i.d.Status {
+	case textdiff.StatusAdded:
+		return "added upstream"
+	case textdiff.StatusDeleted:
+		return "removed upstream"
+	default:
+		return "modified"
+	}
+}
+func (i diffItem) FilterValue() string { return i.d.Path }
+
+type diffModel struct {
+	title string
+	files list.Model
+	vp    viewport.Model
+
+	// Layout
+	bodyH  int
+	listW  int
+	paneW  int
+	selKey string
+}
+
+func newDiffModel(title string, diffs []textdiff.FileDiff) diffModel {
+	items := make([]list.Item, 0, len(diffs))
+	for _, d := range diffs {
+		items = append(items, diffItem{d: d})
+	}
+
+	l := list.New(items, newTerminalListDelegate(), 0, 0)
+	l.SetShowHelp(false)
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetShowPagination(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowFilter(false)
+
+	return diffModel{
+		title: title,
+		files: l,
+		vp:    viewport.New(0, 0),
+	}
+}
+
+func (m diffModel) Init() tea.Cmd { return nil }
+
+func (m diffModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Layout: title + blank line + body + blank line + help.
+		m.bodyH = msg.Height - 4
+		if m.bodyH < 1 {
+			m.bodyH = 1
+		}
+		m.listW = fixedListWidth
+		if msg.Width < fixedListWidth+minPreviewPaneWidth {
+			m.listW = msg.Width
+			m.paneW = 0
+		} else {
+			m.paneW = msg.Width - m.listW
+		}
+		m.files.SetSize(m.listW, m.bodyH)
+
+		// Diffs aren't reflowed, so use the whole pane rather than the
+		// markdown wrap width.
+		m.vp.Width = max(1, m.paneW-2)
+		m.vp.Height = max(1, m.bodyH-1)
+		m.selKey = ""
+		m.renderSelected()
+		return m, nil
+
+	case tea.MouseMsg:
+		if msg.X >= m.listW && m.paneW > 0 {
+			var cmd tea.Cmd
+			m.vp, cmd = m.vp.Update(msg)
+			return m, cmd
+		}
+		var cmd tea.Cmd
+		m.files, cmd = m.files.Update(msg)
+		m.renderSelected()
+		return m, cmd
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, tea.Quit
+		case "pgdown", "space", "f":
+			m.vp.PageDown()
+			return m, nil
+		case "pgup", "b":
+			m.vp.PageUp()
+			return m, nil
+		case "ctrl+d":
+			m.vp.HalfPageDown()
+			return m, nil
+		case "ctrl+u":
+			m.vp.HalfPageUp()
+			return m, nil
+		case "home", "g":
+			m.vp.GotoTop()
+			return m, nil
+		case "end", "G":
+			m.vp.GotoBottom()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.files, cmd = m.files.Update(msg)
+	m.renderSelected()
+	return m, cmd
+}
+
+// renderSelected loads the selected file's diff into the viewport if the
+// selection changed.
+func (m *diffModel) renderSelected() {
+	it, ok := m.files.SelectedItem().(diffItem)
+	if !ok || m.paneW <= 0 {
+		return
+	}
+	if it.d.Path == m.selKey {
+		return
+	}
+	m.selKey = it.d.Path
+
+	m.vp.SetContent(colorDiff(it.d.Unified))
+	m.vp.GotoTop()
+}
+
+// colorDiff colours each line of a unified diff by its first character.
+// The diff isn't rendered as markdown: its lines are file contents, which
+// may well contain code fences of their own.
+func colorDiff(unified string) string {
+	header := lipgloss.NewStyle().Bold(true)
+	hunk := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	added := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	removed := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	lines := strings.Split(strings.TrimSuffix(unified, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			lines[i] = header.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = hunk.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = added.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = removed.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m diffModel) View() string {
+	title := fmt.Sprintf("%s • %d file(s) changed", m.title, len(m.files.Items()))
+	help := "↑/↓ select file • PgUp/PgDn scroll • q to quit"
+
+	left := lipgloss.NewStyle().
+		Width(m.listW).
+		MaxWidth(m.listW).
+		MaxHeight(m.bodyH).
+		Render(m.files.View())
+	body := left
+	if m.paneW > 0 {
+		right := lipgloss.NewStyle().
+			Width(m.paneW).
+			MaxWidth(m.paneW).
+			MaxHeight(m.bodyH).
+			PaddingLeft(2).
+			Render(m.vp.View() + "\n" + scrollIndicatorLine(m.vp))
+		body = lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	}
+
+	return strings.Join([]string{title, "", body, "", help}, "\n")
+}
@@ -0,0 +1,17 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestColorDiff_KeepsEveryLineOfFencedContent(t *testing.T) {
+	unified := "--- a/SKILL.md\n+++ b/SKILL.md\n@@ -1,4 +1,4 @@\n ```bash\n-old\n+new\n ```\n after\n"
+
+	got := ansi.Strip(colorDiff(unified))
+	if want := strings.TrimSuffix(unified, "\n"); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
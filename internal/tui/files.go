@@ -0,0 +1,416 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaofelix/skulls/internal/fsutil"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+// maxPreviewFileSize caps the files shown in the file tree preview.
+const maxPreviewFileSize = skillsapi.MaxPreviewFileSize
+
+// codeStyleName is the chroma style for highlighted files. Its colours are
+// mapped onto the terminal's 16-colour palette, like the ANSI markdown style.
+const codeStyleName = "monokai"
+
+type filesResultMsg struct {
+	seq   int
+	key   string
+	files skillsapi.SkillFiles
+	err   error
+}
+
+type fileContentMsg struct {
+	seq  int
+	body string
+	err  error
+}
+
+// fileRow is a line of the file tree: a directory, or files.Files[file].
+type fileRow struct {
+	depth int
+	name  string
+	file  int // -1 for directories
+}
+
+// fileRows lays out sorted slash-separated paths as a tree, with a row for
+// each directory before its contents.
+func fileRows(files []string) []fileRow {
+	var rows []fileRow
+	var prev []string
+	for i, f := range files {
+		parts := strings.Split(f, "/")
+		dirs := parts[:len(parts)-1]
+		common := 0
+		for common < len(dirs) && common < len(prev) && dirs[common] == prev[common] {
+			common++
+		}
+		for d := common; d < len(dirs); d++ {
+			rows = append(rows, fileRow{depth: d, name: dirs[d] + "/", file: -1})
+		}
+		rows = append(rows, fileRow{depth: len(dirs), name: parts[len(parts)-1], file: i})
+		prev = dirs
+	}
+	return rows
+}
+
+// fileFuncsFor picks how to browse the files of it: from disk for installed
+// copies, then the options' funcs, then the registry if it can.
+func (m searchModel) fileFuncsFor(it skillItem) (
+	func(context.Context, skillsapi.Skill) (skillsapi.SkillFiles, error),
+	func(context.Context, skillsapi.Skill, string) (string, error),
+) {
+	if it.local != nil {
+		dir := it.local.Path
+		return func(context.Context, skillsapi.Skill) (skillsapi.SkillFiles, error) {
+			return listLocalSkillFiles(dir, dir, nil)
+		}, readLocalSkillFile
+	}
+	if m.listFilesFunc != nil && m.fetchFileFunc != nil {
+		return m.listFilesFunc, m.fetchFileFunc
+	}
+	if fb, ok := m.registry.(skillsapi.FileBrowser); ok {
+		return fb.ListSkillFiles, fb.FetchSkillFile
+	}
+	return nil, nil
+}
+
+// toggleFileMode switches the preview pane between SKILL.md and the
+// selected skill's file tree.
+func (m *searchModel) toggleFileMode() tea.Cmd {
+	if m.fileMode {
+		return m.exitFileMode()
+	}
+	it, ok := m.results.SelectedItem().(skillItem)
+	if !ok || m.previewPaneW <= 0 {
+		return nil
+	}
+	m.fileMode = true
+	m.openFile = ""
+	// Keys move through the files now, so the input can't take them.
+	m.input.Blur()
+
+	key := previewKeyForItem(it)
+	// Installed copies may have changed on disk since they were listed.
+	if key == m.filesKey && it.local == nil {
+		m.renderFileTree()
+		return nil
+	}
+	m.filesKey = ""
+	m.files = skillsapi.SkillFiles{}
+	m.filesErr = nil
+	m.fileCursor = 0
+	m.filesVP.GotoTop()
+	m.filesVP.SetContent("")
+
+	listFn, _ := m.fileFuncsFor(it)
+	if listFn == nil {
+		m.filesErr = skillsapi.ErrPreviewUnavailable
+		return nil
+	}
+	m.fileSeq++
+	m.filesLoading = true
+	return doListFiles(listFn, it.s, key, m.fileSeq)
+}
+
+func (m *searchModel) exitFileMode() tea.Cmd {
+	m.fileMode = false
+	m.openFile = ""
+	// Drop results still in flight.
+	m.fileSeq++
+	m.filesLoading = false
+	m.fileLoading = false
+	if m.tab == tabInstalled {
+		return nil
+	}
+	return m.input.Focus()
+}
+
+// handleFileKey runs the file tree's keybindings. Every key is consumed so
+// typing doesn't edit the hidden query.
+func (m *searchModel) handleFileKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "ctrl+f":
+		return m.exitFileMode()
+	case "esc", "backspace", "left", "h":
+		if m.openFile == "" {
+			return m.exitFileMode()
+		}
+		m.openFile = ""
+		m.fileSeq++
+		m.fileLoading = false
+		m.renderFileTree()
+		return nil
+	}
+
+	if m.openFile != "" {
+		var cmd tea.Cmd
+		m.filesVP, cmd = m.filesVP.Update(msg)
+		return cmd
+	}
+	if m.filesLoading || len(m.files.Files) == 0 {
+		return nil
+	}
+	switch msg.String() {
+	case "up", "k":
+		m.fileCursor = max(m.fileCursor-1, 0)
+	case "down", "j":
+		m.fileCursor = min(m.fileCursor+1, len(m.files.Files)-1)
+	case "home", "g":
+		m.fileCursor = 0
+	case "end", "G":
+		m.fileCursor = len(m.files.Files) - 1
+	case "enter", "right", "l":
+		return m.openSelectedFile()
+	default:
+		return nil
+	}
+	m.renderFileTree()
+	return nil
+}
+
+func (m *searchModel) openSelectedFile() tea.Cmd {
+	it, ok := m.results.SelectedItem().(skillItem)
+	if !ok {
+		return nil
+	}
+	_, fetchFn := m.fileFuncsFor(it)
+	if fetchFn == nil {
+		return nil
+	}
+	m.openFile = m.files.Files[m.fileCursor]
+	m.fileSeq++
+	m.fileLoading = true
+	m.fileErr = nil
+	m.fileBody = ""
+	m.filesVP.GotoTop()
+	m.filesVP.SetContent("")
+	return doFetchFile(fetchFn, it.s, path.Join(m.files.Dir, m.openFile), m.fileSeq)
+}
+
+// renderFileTree shows the tree in filesVP, scrolled to keep the cursor in
+// view.
+func (m *searchModel) renderFileTree() {
+	cursor := lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true)
+	dir := lipgloss.NewStyle().Faint(true)
+
+	lines := []string{}
+	selected := 0
+	for i, r := range fileRows(m.files.Files) {
+		indent := strings.Repeat("  ", r.depth)
+		switch {
+		case r.file < 0:
+			lines = append(lines, "  "+indent+dir.Render(r.name))
+		case r.file == m.fileCursor:
+			selected = i
+			lines = append(lines, cursor.Render("› "+indent+r.name))
+		default:
+			lines = append(lines, "  "+indent+r.name)
+		}
+	}
+	m.filesVP.SetContent(strings.Join(lines, "\n"))
+
+	switch {
+	case selected < m.filesVP.YOffset:
+		m.filesVP.SetYOffset(selected)
+	case selected >= m.filesVP.YOffset+m.filesVP.Height:
+		m.filesVP.SetYOffset(selected - m.filesVP.Height + 1)
+	}
+}
+
+func (m *searchModel) renderOpenFile() {
+	if m.fileLoading || m.fileErr != nil {
+		m.filesVP.SetContent("")
+		return
+	}
+	m.filesVP.SetContent(renderFileContent(m.openFile, m.fileBody, wrapWidthForPreview(m.previewPaneW)))
+}
+
+// rerenderFiles redraws file mode after a resize.
+func (m *searchModel) rerenderFiles() {
+	if m.openFile != "" {
+		m.renderOpenFile()
+	} else {
+		m.renderFileTree()
+	}
+}
+
+// renderFileContent renders markdown like the SKILL.md preview and
+// highlights everything else.
+func renderFileContent(name, body string, wrap int) string {
+	switch {
+	case len(body) > maxPreviewFileSize:
+		return fmt.Sprintf("File too large to preview (%d KB)", len(body)>>10)
+	case strings.ContainsRune(body, 0):
+		return "Binary file not shown"
+	case strings.EqualFold(path.Ext(name), ".md"):
+		if rendered, err := renderMarkdownANSI(body, wrap); err == nil {
+			return rendered
+		}
+		return body
+	}
+	return highlightCode(name, body)
+}
+
+// highlightCode colours src with the chroma lexer for name (or one guessed
+// from the contents), returning it unchanged if none fits.
+func highlightCode(name, src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\t", "    ")
+
+	lexer := lexers.Match(path.Base(name))
+	if lexer == nil {
+		lexer = lexers.Analyse(src)
+	}
+	if lexer == nil {
+		return src
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, src)
+	if err != nil {
+		return src
+	}
+
+	// Format line by line so no colour spans a line break: the viewport
+	// cuts lines apart when scrolling.
+	style := styles.Get(codeStyleName)
+	var b strings.Builder
+	for i, line := range chroma.SplitTokensIntoLines(it.Tokens()) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if n := len(line); n > 0 {
+			line[n-1].Value = strings.TrimSuffix(line[n-1].Value, "\n")
+		}
+		if err := formatters.TTY16.Format(&b, style, chroma.Literator(line...)); err != nil {
+			return src
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (m searchModel) filesView() string {
+	it, _ := m.results.SelectedItem().(skillItem)
+	header := lipgloss.NewStyle().Bold(true)
+
+	switch {
+	case m.filesLoading:
+		return m.spinner.View() + " Loading files…"
+	case m.filesErr != nil:
+		return unavailableText("Files", m.filesErr)
+	case m.openFile == "":
+		if len(m.files.Files) == 0 {
+			return "No files"
+		}
+		return header.Render(it.s.SkillID+"/") + "\n" + m.filesVP.View() + "\n" + scrollIndicatorLine(m.filesVP)
+	case m.fileLoading:
+		return m.spinner.View() + " Loading " + m.openFile + "…"
+	case m.fileErr != nil:
+		return unavailableText(m.openFile, m.fileErr)
+	}
+	return header.Render(m.openFile) + "\n" + m.filesVP.View() + "\n" + scrollIndicatorLine(m.filesVP)
+}
+
+// listLocalSkillFiles lists the files under dir, skipping .git, symlinks
+// (which may point outside the skill) and whatever ignore matches. ignore
+// holds the .gitignore rules of root, the repository dir is in, as
+// installs apply them; it may be nil.
+func listLocalSkillFiles(root string, dir string, ignore *fsutil.Ignore) (skillsapi.SkillFiles, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, p); err == nil && rel != "." && ignore.Match(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return skillsapi.SkillFiles{}, fmt.Errorf("%w: %w", skillsapi.ErrPreviewUnavailable, err)
+	}
+	sort.Strings(files)
+	return skillsapi.SkillFiles{Dir: filepath.ToSlash(dir), Files: files}, nil
+}
+
+// readLocalSkillFile reads a file listed by listLocalSkillFiles, refusing
+// anything over maxPreviewFileSize before reading it.
+func readLocalSkillFile(_ context.Context, _ skillsapi.Skill, p string) (string, error) {
+	f, err := os.Open(filepath.FromSlash(p))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", skillsapi.ErrPreviewUnavailable, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", skillsapi.ErrPreviewUnavailable, err)
+	}
+	if !fi.Mode().IsRegular() {
+		return "", skillsapi.ErrPreviewUnavailable
+	}
+	if fi.Size() > maxPreviewFileSize {
+		return "", fmt.Errorf("file too large to preview (%d KB)", fi.Size()>>10)
+	}
+	// The file may have grown since; renderFileContent rejects the extra byte.
+	b, err := io.ReadAll(io.LimitReader(f, maxPreviewFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", skillsapi.ErrPreviewUnavailable, err)
+	}
+	return string(b), nil
+}
+
+func doListFiles(listFn func(context.Context, skillsapi.Skill) (skillsapi.SkillFiles, error), skill skillsapi.Skill, key string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		files, err := listFn(ctx, skill)
+		return filesResultMsg{seq: seq, key: key, files: files, err: err}
+	}
+}
+
+func doFetchFile(fetchFn func(context.Context, skillsapi.Skill, string) (string, error), skill skillsapi.Skill, repoPath string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+		defer cancel()
+
+		body, err := fetchFn(ctx, skill, repoPath)
+		return fileContentMsg{seq: seq, body: body, err: err}
+	}
+}
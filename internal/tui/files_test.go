@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kaofelix/skulls/internal/fsutil"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+func TestFileRows_GroupsFilesUnderDirectories(t *testing.T) {
+	rows := fileRows([]string{"SKILL.md", "scripts/a.py", "scripts/lib/b.py", "templates/c.txt"})
+
+	var got []string
+	for _, r := range rows {
+		got = append(got, strings.Repeat("  ", r.depth)+r.name)
+	}
+	want := []string{"SKILL.md", "scripts/", "  a.py", "  lib/", "    b.py", "templates/", "  c.txt"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("rows=%q", got)
+	}
+	if rows[4].file != 2 || rows[3].file != -1 {
+		t.Fatalf("unexpected file indexes: %+v", rows)
+	}
+}
+
+func TestHighlightCode_ColoursEachLine(t *testing.T) {
+	out := highlightCode("main.go", "package main\n\n/* a\nb */\nfunc main() {}\n")
+
+	lines := strings.Split(out, "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got %d: %q", len(lines), out)
+	}
+	if !strings.Contains(lines[0], "\x1b[") {
+		t.Fatalf("expected ANSI colours, got %q", lines[0])
+	}
+	// A multi-line comment is closed and reopened around the line break.
+	if !strings.HasSuffix(lines[2], "\x1b[0m") || !strings.HasPrefix(lines[3], "\x1b[") {
+		t.Fatalf("expected colours per line, got %q / %q", lines[2], lines[3])
+	}
+}
+
+func TestRenderFileContent_SkipsBinaryAndLargeFiles(t *testing.T) {
+	if got := renderFileContent("logo.png", "\x89PNG\x00\x01", 80); got != "Binary file not shown" {
+		t.Fatalf("got %q", got)
+	}
+	big := strings.Repeat("x", maxPreviewFileSize+1)
+	if got := renderFileContent("big.txt", big, 80); !strings.HasPrefix(got, "File too large") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestListLocalSkillFiles_SkipsIgnoredFilesAndSymlinks(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "skills", "pdf")
+	for name, body := range map[string]string{
+		".gitignore":                   "*.log\nbuild/\n",
+		"skills/pdf/SKILL.md":          "---\nname: pdf\n---\n",
+		"skills/pdf/scripts/fill.py":   "print()\n",
+		"skills/pdf/debug.log":         "noise\n",
+		"skills/pdf/build/out.bin":     "\x00",
+		"skills/pdf/node_modules/x.js": "x\n",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("/etc/passwd", filepath.Join(dir, "passwd")); err != nil {
+		t.Fatal(err)
+	}
+	ignore, err := fsutil.LoadGitignore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := listLocalSkillFiles(root, dir, ignore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"SKILL.md", "node_modules/x.js", "scripts/fill.py"}
+	if strings.Join(got.Files, " ") != strings.Join(want, " ") {
+		t.Fatalf("files=%v want %v", got.Files, want)
+	}
+}
+
+func TestReadLocalSkillFile_RefusesLargeFilesBeforeReading(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "big.txt")
+	if err := os.WriteFile(p, []byte(strings.Repeat("x", maxPreviewFileSize+1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readLocalSkillFile(context.Background(), skillsapi.Skill{}, p); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Fatalf("expected size error, got %v", err)
+	}
+}
+
+func TestSearchModel_FileModeBrowsesAndOpensFiles(t *testing.T) {
+	var fetched string
+	m := newSearchModelWithOptions(SearchOptions{
+		InitialSkills: []skillsapi.Skill{{SkillID: "pdf", Source: "acme/skills"}},
+		ListFilesFunc: func(context.Context, skillsapi.Skill) (skillsapi.SkillFiles, error) {
+			return skillsapi.SkillFiles{Dir: "skills/pdf", Files: []string{"SKILL.md", "scripts/fill.py"}}, nil
+		},
+		FetchFileFunc: func(_ context.Context, _ skillsapi.Skill, p string) (string, error) {
+			fetched = p
+			return "print('hi')\n", nil
+		},
+	})
+	update := func(msg tea.Msg) tea.Cmd {
+		t.Helper()
+		next, cmd := m.Update(msg)
+		m = next.(searchModel)
+		return cmd
+	}
+	update(tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	for _, msg := range collectMsgs(update(tea.KeyMsg{Type: tea.KeyCtrlF})) {
+		update(msg)
+	}
+	if !m.fileMode || m.input.Focused() {
+		t.Fatalf("expected file mode with the input blurred")
+	}
+	view := m.View()
+	if !strings.Contains(view, "› SKILL.md") || !strings.Contains(view, "scripts/") {
+		t.Fatalf("expected file tree, got:\n%s", view)
+	}
+
+	// Typing moves through the tree instead of editing the query.
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if m.input.Value() != "" || m.fileCursor != 1 {
+		t.Fatalf("query=%q cursor=%d", m.input.Value(), m.fileCursor)
+	}
+
+	for _, msg := range collectMsgs(update(tea.KeyMsg{Type: tea.KeyEnter})) {
+		update(msg)
+	}
+	if fetched != "skills/pdf/scripts/fill.py" {
+		t.Fatalf("fetched %q", fetched)
+	}
+	if view := m.View(); !strings.Contains(view, "scripts/fill.py") || !strings.Contains(view, "hi") {
+		t.Fatalf("expected open file, got:\n%s", view)
+	}
+	if m.result.Selected {
+		t.Fatalf("enter in file mode must not select the skill")
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !m.fileMode || m.openFile != "" {
+		t.Fatalf("expected esc to go back to the tree")
+	}
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.fileMode || !m.input.Focused() {
+		t.Fatalf("expected esc to leave file mode")
+	}
+}
+
+func TestSearchModel_FileModeUnavailableWithoutBrowser(t *testing.T) {
+	m := newSearchModelWithOptions(SearchOptions{
+		InitialSkills: []skillsapi.Skill{{SkillID: "pdf", Source: "acme/skills"}},
+		Registry:      skillsapi.Snapshot{},
+	})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = next.(searchModel)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = next.(searchModel)
+
+	if view := m.View(); !strings.Contains(view, "Files unavailable") {
+		t.Fatalf("expected files unavailable, got:\n%s", view)
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sahilm/fuzzy"
+)
+
+// fuzzyFilter keeps the skills whose ID, name or description fuzzy-match
+// query. Matches on the ID or name rank above description-only ones, and
+// better scores first within each; ties keep their order. The matched
+// characters of the ID are kept on the item for highlighting; items matched
+// only on a field the row doesn't show say which one.
+func fuzzyFilter(items []list.Item, query string) []list.Item {
+	type ranked struct {
+		item        skillItem
+		description bool
+		score       int
+	}
+
+	fields := []string{"", "name", "description"}
+	var matched []ranked
+	for _, it := range items {
+		si, ok := it.(skillItem)
+		if !ok {
+			continue
+		}
+		si.matches = nil
+		si.matchedIn = ""
+
+		r := ranked{item: si}
+		found := false
+		for field, target := range []string{si.s.SkillID, si.s.Name, si.s.Description} {
+			if field == 2 && found {
+				break
+			}
+			ms := fuzzy.Find(query, []string{target})
+			if len(ms) == 0 {
+				continue
+			}
+			if field == 0 {
+				r.item.matches = ms[0].MatchedIndexes
+			}
+			if !found {
+				r.item.matchedIn = fields[field]
+			}
+			if !found || ms[0].Score > r.score {
+				r.score = ms[0].Score
+			}
+			r.description = field == 2
+			found = true
+		}
+		if found {
+			matched = append(matched, r)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].description != matched[j].description {
+			return !matched[i].description
+		}
+		return matched[i].score > matched[j].score
+	})
+
+	out := make([]list.Item, 0, len(matched))
+	for _, r := range matched {
+		out = append(out, r.item)
+	}
+	return out
+}
+
+// skillDelegate renders like list.DefaultDelegate, but underlines the title
+// characters a fuzzy filter matched; the list's own filtering is off.
+type skillDelegate struct {
+	list.DefaultDelegate
+}
+
+func (d skillDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	it, ok := item.(skillItem)
+	if !ok || len(it.matches) == 0 || m.Width() <= 0 {
+		d.DefaultDelegate.Render(w, m, index, item)
+		return
+	}
+
+	s := d.Styles
+	textwidth := m.Width() - s.NormalTitle.GetPaddingLeft() - s.NormalTitle.GetPaddingRight()
+	title := ansi.Truncate(it.Title(), textwidth, "…")
+	desc := ansi.Truncate(it.Description(), textwidth, "…")
+
+	titleStyle, descStyle := s.NormalTitle, s.NormalDesc
+	if index == m.Index() {
+		titleStyle, descStyle = s.SelectedTitle, s.SelectedDesc
+	}
+	unmatched := titleStyle.Inline(true)
+	title = lipgloss.StyleRunes(title, it.matches, unmatched.Inherit(s.FilterMatch), unmatched)
+	fmt.Fprintf(w, "%s\n%s", titleStyle.Render(title), descStyle.Render(desc))
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+func TestFuzzyFilter_RanksIDAndNameAboveDescription(t *testing.T) {
+	items := []list.Item{
+		skillItem{s: skillsapi.Skill{SkillID: "release-notes", Description: "Write a pdf changelog"}},
+		skillItem{s: skillsapi.Skill{SkillID: "unrelated", Description: "Nothing to see"}},
+		skillItem{s: skillsapi.Skill{SkillID: "pdf-tools", Description: "Fill forms"}},
+		skillItem{s: skillsapi.Skill{SkillID: "docs", Name: "PDF helper"}},
+	}
+
+	got := fuzzyFilter(items, "pdf")
+
+	var ids []string
+	for _, it := range got {
+		ids = append(ids, it.(skillItem).s.SkillID)
+	}
+	if want := []string{"pdf-tools", "docs", "release-notes"}; !slices.Equal(ids, want) {
+		t.Fatalf("ids=%v want %v", ids, want)
+	}
+	if m := got[0].(skillItem).matches; !slices.Equal(m, []int{0, 1, 2}) {
+		t.Fatalf("expected ID matches to be highlighted, got %v", m)
+	}
+	if m := got[2].(skillItem).matches; m != nil {
+		t.Fatalf("description-only match must not highlight the title, got %v", m)
+	}
+	for i, want := range []string{"", "name", "description"} {
+		if it := got[i].(skillItem); it.matchedIn != want {
+			t.Fatalf("%s: matchedIn=%q want %q", it.s.SkillID, it.matchedIn, want)
+		}
+	}
+	if d := got[2].(skillItem).Description(); !strings.HasSuffix(d, "matches description") {
+		t.Fatalf("expected description match to be labelled, got %q", d)
+	}
+}
+
+func TestSearchModel_SourceFilterIsFuzzyAndCountsMatches(t *testing.T) {
+	m := newSearchModelWithOptions(SearchOptions{
+		InitialSkills: []skillsapi.Skill{
+			{SkillID: "brainstorming", Source: "obra/superpowers"},
+			{SkillID: "test-driven-development", Source: "obra/superpowers"},
+			{SkillID: "using-git-worktrees", Source: "obra/superpowers", Description: "Isolate feature work"},
+		},
+	})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = next.(searchModel)
+
+	for _, r := range "tdd" {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = next.(searchModel)
+	}
+	items := m.results.Items()
+	if len(items) != 1 || items[0].(skillItem).s.SkillID != "test-driven-development" {
+		t.Fatalf("items=%v", items)
+	}
+	if view := m.View(); !strings.Contains(view, "1 of 3 match") {
+		t.Fatalf("expected match count, got:\n%s", view)
+	}
+
+	for range 3 {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		m = next.(searchModel)
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("isolate")})
+	m = next.(searchModel)
+	if items := m.results.Items(); len(items) != 1 || items[0].(skillItem).s.SkillID != "using-git-worktrees" {
+		t.Fatalf("expected description match, got %v", items)
+	}
+}
@@ -1,16 +1,20 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/kaofelix/skulls/internal/gitutil"
 	"github.com/kaofelix/skulls/internal/install"
 	"github.com/kaofelix/skulls/internal/skillsapi"
 )
@@ -22,8 +26,11 @@ type InstallResult struct {
 
 // RunInstall shows an install progress UI in the *normal* terminal screen (no alt screen).
 // It exits when the install is complete, leaving the final checklist visible in scrollback.
-func RunInstall(targetDir string, force bool, skill skillsapi.Skill) (InstallResult, error) {
-	m := newInstallModel(targetDir, force, skill)
+//
+// opts.Progress and the git output writers are owned by the UI and overridden.
+// Ctrl+C cancels ctx for the install and waits for it to clean up.
+func RunInstall(ctx context.Context, skill skillsapi.Skill, opts install.Options) (InstallResult, error) {
+	m := newInstallModel(ctx, skill, opts)
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
@@ -36,6 +43,9 @@ func RunInstall(targetDir string, force bool, skill skillsapi.Skill) (InstallRes
 	return InstallResult{InstalledPath: fm.installedPath, Err: fm.err}, nil
 }
 
+// gitOutputTailLines is how much of git's output is shown when it fails.
+const gitOutputTailLines = 5
+
 type installEventMsg install.Event
 
 type installDoneMsg struct {
@@ -44,38 +54,43 @@ type installDoneMsg struct {
 }
 
 type installModel struct {
-	targetDir string
-	force     bool
-	skill     skillsapi.Skill
+	opts  install.Options
+	skill skillsapi.Skill
 
 	spin spinner.Model
+	bar  progress.Model
 
 	steps map[install.Step]install.Event
-	order []install.Step
+	// transfer is the latest git progress of the running clone.
+	transfer *gitutil.Progress
+	order    []install.Step
 
 	installedPath string
 	err           error
 
-	msgCh <-chan tea.Msg
+	msgCh      <-chan tea.Msg
+	cancel     context.CancelFunc
+	cancelling bool
 }
 
-func newInstallModel(targetDir string, force bool, skill skillsapi.Skill) installModel {
+func newInstallModel(ctx context.Context, skill skillsapi.Skill, opts install.Options) installModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 
 	m := installModel{
-		targetDir: targetDir,
-		force:     force,
-		skill:     skill,
-		spin:      s,
-		steps:     map[install.Step]install.Event{},
+		opts:  opts,
+		skill: skill,
+		spin:  s,
+		bar:   newTransferBar(),
+		steps: map[install.Step]install.Event{},
 		order: []install.Step{
 			install.StepClone,
 			install.StepVerify,
 			install.StepCopy,
 		},
 	}
-	m.msgCh = startInstall(m.targetDir, m.force, m.skill)
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.msgCh = startInstall(ctx, m.skill, m.opts)
 	return m
 }
 
@@ -87,8 +102,10 @@ func (m installModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
-			m.err = tea.ErrProgramKilled
-			return m, tea.Quit
+			// Let the install remove its temp dirs before quitting; it
+			// reports back with installDoneMsg.
+			m.cancelling = true
+			m.cancel()
 		}
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -97,22 +114,24 @@ func (m installModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case installEventMsg:
 		e := install.Event(msg)
 		m.steps[e.Step] = e
-		// If we see StepRemove, include it in the order (it only appears when needed).
-		if e.Step == install.StepRemove && !containsStep(m.order, install.StepRemove) {
-			// Insert before copy.
-			newOrder := []install.Step{}
-			for _, s := range m.order {
-				if s == install.StepCopy {
-					newOrder = append(newOrder, install.StepRemove)
-				}
-				newOrder = append(newOrder, s)
-			}
-			m.order = newOrder
+		if e.Step == install.StepClone {
+			m.transfer = e.Progress
+		}
+		// Optional steps only appear when needed; slot them into the timeline.
+		switch e.Step {
+		case install.StepRemove:
+			m.order = insertStepBefore(m.order, install.StepRemove, install.StepCopy)
+		case install.StepSignature:
+			m.order = insertStepBefore(m.order, install.StepSignature, install.StepVerify)
 		}
 		return m, waitMsg(m.msgCh)
 	case installDoneMsg:
+		m.cancel()
 		m.installedPath = msg.path
 		m.err = msg.err
+		if m.cancelling && errors.Is(m.err, context.Canceled) {
+			m.err = tea.ErrProgramKilled
+		}
 		return m, tea.Quit
 	}
 
@@ -139,7 +158,7 @@ func (m installModel) View() string {
 	}
 	b.WriteString("\n")
 	b.WriteString("Skill:       " + strings.TrimSpace(m.skill.SkillID) + "\n")
-	b.WriteString("Install dir: " + compactPath(m.targetDir) + "\n")
+	b.WriteString("Install dir: " + compactPath(m.opts.TargetDir) + "\n")
 	b.WriteString("\n")
 
 	for i, step := range m.order {
@@ -156,6 +175,9 @@ func (m installModel) View() string {
 			b.WriteString(ok.Render("◆ "+label) + "\n")
 		default:
 			b.WriteString(pending.Render(m.spin.View()+" "+label) + "\n")
+			if step == install.StepClone && m.transfer != nil {
+				b.WriteString(lineStyle.Render("│") + " " + m.bar.ViewAs(float64(m.transfer.Percent)/100) + " " + muted.Render(transferLabel(*m.transfer)) + "\n")
+			}
 		}
 
 		if i < len(m.order)-1 {
@@ -163,13 +185,39 @@ func (m installModel) View() string {
 		}
 	}
 
+	if m.cancelling && m.err == nil && m.installedPath == "" {
+		b.WriteString("\n" + muted.Render("Cancelling…") + "\n")
+	}
 	if m.err != nil {
 		b.WriteString("\n" + bad.Render("✗ "+m.err.Error()) + "\n")
+		var gitErr *gitutil.GitError
+		if errors.As(m.err, &gitErr) {
+			if hint := gitErr.Hint(); hint != "" {
+				b.WriteString("  " + hint + "\n")
+			}
+			for _, line := range gitErr.Tail(gitOutputTailLines) {
+				b.WriteString(muted.Render("  │ "+line) + "\n")
+			}
+		}
 	}
 
 	return b.String()
 }
 
+func newTransferBar() progress.Model {
+	return progress.New(progress.WithDefaultGradient(), progress.WithWidth(30), progress.WithoutPercentage())
+}
+
+// transferLabel describes git progress next to the bar, e.g.
+// "Receiving objects 45% · 1.20 MiB".
+func transferLabel(p gitutil.Progress) string {
+	label := fmt.Sprintf("%s %d%%", p.Phase, p.Percent)
+	if p.Bytes > 0 {
+		label += " · " + gitutil.FormatBytes(p.Bytes)
+	}
+	return label
+}
+
 func skullsBanner() string {
 	return strings.Trim(`
          █████                 ████  ████               ▄▄▄▄
@@ -214,18 +262,15 @@ func compactPath(path string) string {
 	return abs
 }
 
-func startInstall(targetDir string, force bool, skill skillsapi.Skill) <-chan tea.Msg {
+func startInstall(ctx context.Context, skill skillsapi.Skill, opts install.Options) <-chan tea.Msg {
 	ch := make(chan tea.Msg, 128)
+	opts.GitStdout = io.Discard
+	opts.GitStderr = io.Discard
+	opts.Progress = func(e install.Event) {
+		ch <- installEventMsg(e)
+	}
 	go func() {
-		path, err := install.InstallSkill(skill.Source, skill.SkillID, install.Options{
-			TargetDir: targetDir,
-			Force:     force,
-			GitStdout: io.Discard,
-			GitStderr: io.Discard,
-			Progress: func(e install.Event) {
-				ch <- installEventMsg(e)
-			},
-		})
+		path, err := install.InstallSkill(ctx, skill.Source, skill.SkillID, opts)
 		ch <- installDoneMsg{path: path, err: err}
 		close(ch)
 	}()
@@ -238,6 +283,8 @@ func stepLabel(step install.Step) string {
 		return "Normalize source"
 	case install.StepClone:
 		return "Clone repository"
+	case install.StepSignature:
+		return "Verify signature"
 	case install.StepVerify:
 		return "Verify skill layout"
 	case install.StepRemove:
@@ -249,6 +296,20 @@ func stepLabel(step install.Step) string {
 	}
 }
 
+func insertStepBefore(order []install.Step, step install.Step, before install.Step) []install.Step {
+	if containsStep(order, step) {
+		return order
+	}
+	out := make([]install.Step, 0, len(order)+1)
+	for _, s := range order {
+		if s == before {
+			out = append(out, step)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
 func containsStep(steps []install.Step, s install.Step) bool {
 	for _, x := range steps {
 		if x == s {
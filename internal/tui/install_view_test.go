@@ -1,11 +1,14 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/spinner"
 
+	"github.com/kaofelix/skulls/internal/gitutil"
 	"github.com/kaofelix/skulls/internal/install"
 	"github.com/kaofelix/skulls/internal/skillsapi"
 )
@@ -15,9 +18,9 @@ func TestInstallView_ShowsBannerAndContext(t *testing.T) {
 	s.Spinner = spinner.Dot
 
 	m := installModel{
-		targetDir: "~/agent/skills",
-		skill:     skillsapi.Skill{SkillID: "demo-skill", Source: "owner/repo"},
-		spin:      s,
+		opts:  install.Options{TargetDir: "~/agent/skills"},
+		skill: skillsapi.Skill{SkillID: "demo-skill", Source: "owner/repo"},
+		spin:  s,
 		steps: map[install.Step]install.Event{
 			install.StepNormalize: {Step: install.StepNormalize, Message: "Source normalized", Done: true},
 		},
@@ -37,7 +40,7 @@ func TestInstallView_ShowsBannerAndContext(t *testing.T) {
 }
 
 func TestNewInstallModel_HidesNormalizeStepFromTimeline(t *testing.T) {
-	m := newInstallModel("/tmp/skills", false, skillsapi.Skill{SkillID: "demo", Source: "owner/repo"})
+	m := newInstallModel(context.Background(), skillsapi.Skill{SkillID: "demo", Source: "owner/repo"}, install.Options{TargetDir: "/tmp/skills"})
 	for _, step := range m.order {
 		if step == install.StepNormalize {
 			t.Fatalf("normalize step should not be shown in timeline order: %+v", m.order)
@@ -72,3 +75,84 @@ func TestInstallView_UsesConnectedStepTimeline(t *testing.T) {
 		t.Fatalf("expected separate repository-cloned line to be gone: %q", view)
 	}
 }
+
+func TestInstallView_ExplainsGitErrors(t *testing.T) {
+	m := installModel{
+		skill: skillsapi.Skill{SkillID: "demo"},
+		steps: map[install.Step]install.Event{},
+		err: &gitutil.GitError{
+			Command: "clone",
+			Kind:    gitutil.ErrRepoNotFound,
+			Output:  "Cloning into 'repo'...\nremote: Repository not found.\nfatal: repository 'https://github.com/o/r.git/' not found\n",
+			Err:     errors.New("exit status 128"),
+		},
+	}
+
+	view := m.View()
+	for _, want := range []string{"git clone failed: repository not found", "Check the source for typos", "remote: Repository not found."} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in view: %q", want, view)
+		}
+	}
+	if strings.Contains(view, "exit status 128") {
+		t.Fatalf("expected the bare exit status to be hidden: %q", view)
+	}
+}
+
+func TestInstallView_ShowsCloneProgressBar(t *testing.T) {
+	m := newInstallModel(context.Background(), skillsapi.Skill{SkillID: "demo"}, install.Options{TargetDir: t.TempDir()})
+	m.cancel()
+	next, _ := m.Update(installEventMsg(install.Event{
+		Step:     install.StepClone,
+		Message:  "Cloning repository (full)",
+		Progress: &gitutil.Progress{Phase: "Receiving objects", Percent: 45, Current: 45, Total: 100, Bytes: 3 << 19},
+	}))
+	view := next.(installModel).View()
+	if !strings.Contains(view, "Receiving objects 45% · 1.50 MiB") {
+		t.Fatalf("expected transfer label in view: %q", view)
+	}
+	if !strings.Contains(view, "█") && !strings.Contains(view, "░") {
+		t.Fatalf("expected a progress bar in view: %q", view)
+	}
+
+	next, _ = next.Update(installEventMsg(install.Event{Step: install.StepClone, Message: "Cloned", Done: true}))
+	if view := next.(installModel).View(); strings.Contains(view, "Receiving objects") {
+		t.Fatalf("expected the bar to go away once cloned: %q", view)
+	}
+}
+
+func TestBatchView_ShowsRowPerItemAndSummary(t *testing.T) {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+
+	items := []install.BatchItem{
+		{Source: "owner/repo", SkillID: "alpha"},
+		{Source: "owner/repo", SkillID: "beta"},
+		{Source: "other/repo", SkillID: "gamma"},
+	}
+	m := batchModel{
+		opts:   install.BatchOptions{Options: install.Options{TargetDir: "/tmp/skills"}},
+		items:  items,
+		spin:   s,
+		latest: make([]*install.Event, len(items)),
+	}
+	m.latest[0] = &install.Event{Step: install.StepClone, Progress: &gitutil.Progress{Phase: "Receiving objects", Percent: 45}}
+
+	view := m.View()
+	if !strings.Contains(view, "owner/repo@alpha") || !strings.Contains(view, "Receiving objects 45%") {
+		t.Fatalf("expected progress row for alpha, got:\n%s", view)
+	}
+	if !strings.Contains(view, "other/repo@gamma  Waiting") {
+		t.Fatalf("expected waiting row for gamma, got:\n%s", view)
+	}
+
+	m.results = []install.BatchResult{
+		{Item: items[0], InstalledPath: "/tmp/skills/alpha"},
+		{Item: items[1], Err: &gitutil.GitError{Command: "clone", Kind: gitutil.ErrRepoNotFound}},
+		{Item: items[2], InstalledPath: "/tmp/skills/gamma"},
+	}
+	view = m.View()
+	if !strings.Contains(view, "✗ owner/repo@beta") || !strings.Contains(view, "2 installed, 1 failed") {
+		t.Fatalf("expected final report, got:\n%s", view)
+	}
+}
@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaofelix/skulls/internal/install"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+// searchTab is a tab of the registry search UI.
+type searchTab int
+
+const (
+	tabPopular searchTab = iota
+	tabSearch
+	tabInstalled
+)
+
+func (t searchTab) String() string {
+	switch t {
+	case tabPopular:
+		return "Popular"
+	case tabSearch:
+		return "Search"
+	case tabInstalled:
+		return "Installed"
+	default:
+		return ""
+	}
+}
+
+// Action is what the caller of the search UI should do with its result.
+type Action int
+
+const (
+	// ActionInstall installs SearchResult.Skill.
+	ActionInstall Action = iota
+	// ActionUpdate reinstalls SearchResult.Installed from its recorded
+	// source and ref, at whatever commit the ref points to now.
+	ActionUpdate
+	// ActionReinstall reinstalls SearchResult.Installed exactly as
+	// recorded, at the same commit.
+	ActionReinstall
+)
+
+type editorDoneMsg struct {
+	err error
+}
+
+// loadInstalled reads the skills in the target dir.
+func (m *searchModel) loadInstalled() {
+	m.installed = map[string]install.InstalledSkill{}
+	if m.targetDir == "" {
+		return
+	}
+	// An unreadable target dir just means nothing is marked installed.
+	installed, _ := install.ListInstalled(m.targetDir)
+	for _, s := range installed {
+		m.installed[s.Folder] = s
+	}
+}
+
+// reloadInstalled re-reads the target dir after a change and re-marks
+// every list.
+func (m *searchModel) reloadInstalled() {
+	m.loadInstalled()
+	m.allItems = m.withInstallStates(m.allItems)
+	m.popularItems = m.withInstallStates(m.popularItems)
+	m.searchItems = m.withInstallStates(m.searchItems)
+	if m.tab == tabInstalled {
+		m.setResults(m.installedItems())
+	} else {
+		m.setResults(m.withInstallStates(m.shown))
+	}
+}
+
+// installedItems lists the installed skills for the Installed tab.
+func (m searchModel) installedItems() []list.Item {
+	folders := make([]string, 0, len(m.installed))
+	for folder := range m.installed {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+
+	items := make([]list.Item, 0, len(folders))
+	for _, folder := range folders {
+		inst := m.installed[folder]
+		s := skillsapi.Skill{SkillID: inst.SkillID(), Name: inst.Name, Description: inst.Description}
+		if md := inst.Metadata; md != nil {
+			s.Source = md.Source
+			s.Ref = md.Ref
+			s.Path = md.Path
+		}
+		it := skillItem{s: s, local: &inst}
+		it.installed, it.installedFrom = m.installStateOf(s)
+		items = append(items, it)
+	}
+	return items
+}
+
+// switchTab moves delta tabs over, wrapping around.
+func (m *searchModel) switchTab(delta int) tea.Cmd {
+	i := slices.Index(m.tabs, m.tab)
+	m.tab = m.tabs[(i+delta+len(m.tabs))%len(m.tabs)]
+	m.confirmRemove = ""
+
+	var cmds []tea.Cmd
+	switch m.tab {
+	case tabInstalled:
+		// Keys act on the selected skill here, so the input can't take them.
+		m.input.Blur()
+		items := m.installedItems()
+		m.setResults(items)
+		cmds = append(cmds, m.checkUpdates(items))
+	case tabPopular:
+		cmds = append(cmds, m.input.Focus())
+		m.setResults(m.popularItems)
+		if m.popularItems == nil && !m.popularLoading {
+			m.popularLoading = true
+			cmds = append(cmds, doPopular(m.registry, m.popularPage.want))
+		}
+	case tabSearch:
+		cmds = append(cmds, m.input.Focus())
+		m.setResults(m.searchItems)
+	}
+	return tea.Batch(append(cmds, m.ensurePreviewForSelection())...)
+}
+
+// handleInstalledKey runs the Installed tab's keybindings on the selected
+// skill and reports whether key was one of them.
+func (m *searchModel) handleInstalledKey(key string) (tea.Cmd, bool) {
+	confirm := m.confirmRemove
+	m.confirmRemove = ""
+	it, ok := m.results.SelectedItem().(skillItem)
+	if !ok || it.local == nil {
+		return nil, false
+	}
+	inst := *it.local
+
+	switch key {
+	case "x", "delete":
+		if confirm != inst.Folder {
+			m.confirmRemove = inst.Folder
+			m.notice = fmt.Sprintf("Remove %s? Press x again to confirm", inst.SkillID())
+			return nil, true
+		}
+		if err := install.Uninstall(m.targetDir, inst.Folder); err != nil {
+			m.notice = "Error: " + err.Error()
+			return nil, true
+		}
+		m.reloadInstalled()
+		m.notice = "Removed " + inst.SkillID()
+		return m.ensurePreviewForSelection(), true
+	case "u", "r":
+		if inst.Metadata == nil {
+			m.notice = inst.SkillID() + " has no install metadata; reinstall it with skulls add"
+			return nil, true
+		}
+		action := ActionUpdate
+		if key == "r" {
+			action = ActionReinstall
+		}
+		m.result = SearchResult{Selected: true, Skill: it.s, Action: action, Installed: &inst}
+		return tea.Quit, true
+	case "e":
+		cmd, err := editorCommand(inst.Path)
+		if err != nil {
+			m.notice = err.Error()
+			return nil, true
+		}
+		return tea.ExecProcess(cmd, func(err error) tea.Msg { return editorDoneMsg{err: err} }), true
+	case "enter":
+		m.notice = "x remove • u update • r reinstall • e edit"
+		return nil, true
+	}
+	return nil, false
+}
+
+// editorCommand opens dir in $EDITOR, which may include arguments.
+func editorCommand(dir string) (*exec.Cmd, error) {
+	args := strings.Fields(os.Getenv("EDITOR"))
+	if len(args) == 0 {
+		return nil, errors.New("set $EDITOR to open skills in your editor")
+	}
+	return exec.Command(args[0], append(args[1:], dir)...), nil
+}
+
+func (m searchModel) tabsView() string {
+	if len(m.tabs) == 0 {
+		return ""
+	}
+	active := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	inactive := lipgloss.NewStyle().Faint(true)
+
+	parts := make([]string, 0, len(m.tabs))
+	for _, t := range m.tabs {
+		label := t.String()
+		if t == tabInstalled {
+			label += fmt.Sprintf(" (%d)", len(m.installed))
+		}
+		if t == m.tab {
+			parts = append(parts, active.Render("["+label+"]"))
+		} else {
+			parts = append(parts, inactive.Render(" "+label+" "))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -17,12 +18,19 @@ import (
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/kaofelix/skulls/internal/install"
+	"github.com/kaofelix/skulls/internal/policy"
 	"github.com/kaofelix/skulls/internal/skillsapi"
 )
 
 type SearchResult struct {
 	Selected bool
 	Skill    skillsapi.Skill
+
+	// Action is what to do with Skill. Installed is the installed skill the
+	// action applies to, for ActionUpdate and ActionReinstall.
+	Action    Action
+	Installed *install.InstalledSkill
 }
 
 type SearchOptions struct {
@@ -31,8 +39,47 @@ type SearchOptions struct {
 	StatusHint    string
 	SearchFunc    func(context.Context, string, int) ([]skillsapi.Skill, error)
 	PreviewFunc   func(context.Context, skillsapi.Skill) (string, error)
+
+	// Registry serves popular, search and preview requests not covered by the
+	// funcs above. Defaults to skills.sh.
+	Registry skillsapi.Registry
+
+	// SnapshotAt, if set, marks the registry as an offline snapshot taken at
+	// that time; its age is shown in the status line.
+	SnapshotAt time.Time
+
+	// Policy marks (or hides, see policy.Policy.HideDenied) skills whose
+	// source is not allowed. Denied skills can't be selected.
+	Policy policy.Set
+
+	// TargetDir, if set, marks skills already installed there, noting when
+	// they came from a different source, and adds an Installed tab to manage
+	// them.
+	TargetDir string
+
+	// CheckUpdate, if set, reports whether an installed skill has an update
+	// available. It runs in the background for installed rows.
+	CheckUpdate func(context.Context, install.Metadata) (bool, error)
+
+	// ListFilesFunc and FetchFileFunc back the file tree preview (Ctrl+F).
+	// FetchFileFunc gets SkillFiles.Dir joined with one of its Files. They
+	// default to the registry when it is a skillsapi.FileBrowser.
+	ListFilesFunc func(context.Context, skillsapi.Skill) (skillsapi.SkillFiles, error)
+	FetchFileFunc func(context.Context, skillsapi.Skill, string) (string, error)
+
+	// SearchLimit and PopularLimit are how many results a search and the
+	// popular list load at first, and how many more each time the selection
+	// reaches the end of the list. They default to DefaultSearchLimit and
+	// DefaultPopularLimit.
+	SearchLimit  int
+	PopularLimit int
 }
 
+const (
+	DefaultSearchLimit  = 10
+	DefaultPopularLimit = 50
+)
+
 // RunSearch runs the interactive search UI in the alt screen and returns the selected skill.
 func RunSearch() (SearchResult, error) {
 	return RunSearchWithOptions(SearchOptions{})
@@ -52,21 +99,78 @@ func RunSearchWithOptions(opts SearchOptions) (SearchResult, error) {
 	return fm.result, nil
 }
 
-type skillItem struct{ s skillsapi.Skill }
+// installState is how a search result relates to the installed skills.
+type installState int
+
+const (
+	notInstalled installState = iota
+	installedSame
+	installedOtherSource
+	installedUpdate
+)
+
+type skillItem struct {
+	s      skillsapi.Skill
+	denied error
+	// sourceErr is set when the source couldn't be checked against the
+	// policy at all, e.g. because it isn't a recognized source.
+	sourceErr error
+
+	installed installState
+	// installedFrom is the installed copy's source, for installedOtherSource.
+	installedFrom string
+
+	// local is set for rows of the Installed tab.
+	local *install.InstalledSkill
+
+	// matches are the title runes the filter query matched.
+	matches []int
+	// matchedIn names the field the filter query matched when it isn't the
+	// title: "name" or "description".
+	matchedIn string
+}
 
 func (i skillItem) Title() string { return i.s.SkillID }
 func (i skillItem) Description() string {
 	parts := []string{}
+	if i.denied != nil {
+		parts = append(parts, "⛔ blocked by policy")
+	}
+	if i.sourceErr != nil {
+		parts = append(parts, "⚠ unrecognized source")
+	}
+	switch i.installed {
+	case installedSame:
+		parts = append(parts, "✓ installed")
+	case installedOtherSource:
+		parts = append(parts, "✓ installed from "+i.installedFrom)
+	case installedUpdate:
+		parts = append(parts, "↑ update available")
+	}
 	if i.s.Source != "" {
 		parts = append(parts, i.s.Source)
 	}
 	if i.s.Installs > 0 {
 		parts = append(parts, fmt.Sprintf("%d installs", i.s.Installs))
 	}
+	if i.s.Registry != "" {
+		parts = append(parts, "["+i.s.Registry+"]")
+	}
+	if i.matchedIn != "" {
+		parts = append(parts, "matches "+i.matchedIn)
+	}
 	return strings.Join(parts, " • ")
 }
 func (i skillItem) FilterValue() string { return i.s.SkillID }
 
+// pager tracks how many results a list asked for and whether it may have
+// more.
+type pager struct {
+	want    int
+	more    bool // the last response was full
+	loading bool // a bigger page is on its way
+}
+
 type searchResultMsg struct {
 	seq    int
 	skills []skillsapi.Skill
@@ -83,6 +187,11 @@ type triggerSearchMsg struct {
 	query string
 }
 
+type updateCheckMsg struct {
+	folder string
+	update bool
+}
+
 type previewResultMsg struct {
 	seq int
 	key string
@@ -91,7 +200,7 @@ type previewResultMsg struct {
 }
 
 type searchModel struct {
-	client skillsapi.Client
+	registry skillsapi.Registry
 
 	input       textinput.Model
 	results     list.Model
@@ -103,11 +212,39 @@ type searchModel struct {
 	statusHint  string
 	searchFunc  func(context.Context, string, int) ([]skillsapi.Skill, error)
 	previewFunc func(context.Context, skillsapi.Skill) (string, error)
+	policy      policy.Set
+	notice      string
+	snapshotAt  time.Time
+
+	// tabs is nil when browsing skills from a single source.
+	tabs          []searchTab
+	tab           searchTab
+	searchItems   []list.Item
+	confirmRemove string
+
+	// installed maps folder names to the skills in targetDir.
+	targetDir   string
+	installed   map[string]install.InstalledSkill
+	checkUpdate func(context.Context, install.Metadata) (bool, error)
+	// updateChecked holds folders whose update check ran or is running;
+	// updates those that have an update.
+	updateChecked map[string]bool
+	updates       map[string]bool
+	hideInstalled bool
+	// shown is what the results list shows before hiding installed skills.
+	shown []list.Item
 
 	popularLoading bool
 	popularErr     error
 	popularItems   []list.Item
 
+	// Infinite scroll: page sizes, the query searched, and paging state.
+	searchLimit  int
+	popularLimit int
+	searchQuery  string
+	searchPage   pager
+	popularPage  pager
+
 	// Layout
 	windowW      int
 	windowH      int
@@ -126,6 +263,23 @@ type searchModel struct {
 	previewVP       viewport.Model
 	lastSelKey      string
 
+	// File tree (Ctrl+F): the preview pane lists the selected skill's files
+	// and shows the one opened.
+	listFilesFunc func(context.Context, skillsapi.Skill) (skillsapi.SkillFiles, error)
+	fetchFileFunc func(context.Context, skillsapi.Skill, string) (string, error)
+	fileMode      bool
+	fileSeq       int
+	filesKey      string // preview key of the skill files lists
+	files         skillsapi.SkillFiles
+	filesLoading  bool
+	filesErr      error
+	fileCursor    int
+	openFile      string // relative to files.Dir; "" shows the tree
+	fileLoading   bool
+	fileErr       error
+	fileBody      string
+	filesVP       viewport.Model
+
 	result SearchResult
 }
 
@@ -155,7 +309,7 @@ func newSearchModelWithOptions(opts SearchOptions) searchModel {
 	s := spinner.New()
 	s.Spinner = spinner.Line
 
-	l := list.New([]list.Item{}, newTerminalListDelegate(), 0, 0)
+	l := list.New([]list.Item{}, skillDelegate{newTerminalListDelegate()}, 0, 0)
 	l.SetShowHelp(false)
 	l.SetShowTitle(false)
 	l.SetShowStatusBar(false)
@@ -164,35 +318,62 @@ func newSearchModelWithOptions(opts SearchOptions) searchModel {
 	l.SetShowFilter(false)
 	l.Title = ""
 
-	allItems := make([]list.Item, 0, len(opts.InitialSkills))
-	for _, sk := range opts.InitialSkills {
-		allItems = append(allItems, skillItem{s: sk})
+	var registry skillsapi.Registry = skillsapi.Client{}
+	if opts.Registry != nil {
+		registry = opts.Registry
 	}
 
 	m := searchModel{
-		client:         skillsapi.Client{},
-		popularLoading: len(allItems) == 0,
-		input:          ti,
-		results:        l,
-		allItems:       allItems,
-		spinner:        s,
-		statusHint:     strings.TrimSpace(opts.StatusHint),
-		searchFunc:     opts.SearchFunc,
-		previewFunc:    opts.PreviewFunc,
-		previewCache:   map[string]string{},
-		previewVP:      viewport.New(0, 0),
-	}
-	if len(allItems) > 0 {
-		m.results.SetItems(allItems)
+		registry:     registry,
+		input:        ti,
+		results:      l,
+		spinner:      s,
+		statusHint:   strings.TrimSpace(opts.StatusHint),
+		searchFunc:   opts.SearchFunc,
+		previewFunc:  opts.PreviewFunc,
+		policy:       opts.Policy,
+		snapshotAt:   opts.SnapshotAt,
+		previewCache: map[string]string{},
+		previewVP:    viewport.New(0, 0),
+
+		listFilesFunc: opts.ListFilesFunc,
+		fetchFileFunc: opts.FetchFileFunc,
+		filesVP:       viewport.New(0, 0),
+
+		targetDir:     strings.TrimSpace(opts.TargetDir),
+		checkUpdate:   opts.CheckUpdate,
+		updateChecked: map[string]bool{},
+		updates:       map[string]bool{},
+	}
+	m.searchLimit = opts.SearchLimit
+	if m.searchLimit <= 0 {
+		m.searchLimit = DefaultSearchLimit
+	}
+	m.popularLimit = opts.PopularLimit
+	if m.popularLimit <= 0 {
+		m.popularLimit = DefaultPopularLimit
+	}
+	m.popularPage = pager{want: m.popularLimit}
+
+	m.loadInstalled()
+	m.allItems = m.itemsFor(opts.InitialSkills)
+	m.popularLoading = len(m.allItems) == 0
+	if len(m.allItems) > 0 {
+		m.setResults(m.allItems)
+	} else {
+		m.tabs = []searchTab{tabPopular, tabSearch}
+		if m.targetDir != "" {
+			m.tabs = append(m.tabs, tabInstalled)
+		}
 	}
 	return m
 }
 
 func (m searchModel) Init() tea.Cmd {
 	if len(m.allItems) > 0 {
-		return tea.Batch(textinput.Blink, m.spinner.Tick, m.ensurePreviewForSelection())
+		return tea.Batch(textinput.Blink, m.spinner.Tick, m.ensurePreviewForSelection(), m.checkUpdates(m.allItems))
 	}
-	return tea.Batch(textinput.Blink, m.spinner.Tick, doPopular(m.client, 50))
+	return tea.Batch(textinput.Blink, m.spinner.Tick, doPopular(m.registry, m.popularPage.want))
 }
 
 func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -201,7 +382,7 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.windowW = msg.Width
 		m.windowH = msg.Height
 
-		// Layout: input + blank line + status + blank line + body.
+		// Layout: input + tabs (or blank) line + status + blank line + body.
 		m.bodyH = msg.Height - 4
 		if m.bodyH < 1 {
 			m.bodyH = 1
@@ -230,10 +411,19 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			previewH = 1
 		}
 		m.previewVP.Height = previewH
+		m.filesVP.Width = m.previewVP.Width
+		// File mode adds a header line.
+		m.filesVP.Height = max(previewH-1, 1)
 
 		// If we already have preview markdown, re-render for the new width.
 		m.rerenderPreview()
 
+		if m.fileMode {
+			if m.previewPaneW <= 0 {
+				return m, m.exitFileMode()
+			}
+			m.rerenderFiles()
+		}
 		return m, nil
 
 	case tea.MouseMsg:
@@ -242,7 +432,11 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// If scrolling in the preview pane, scroll preview instead of the list.
 		if m.isInPreviewPane(msg) && msg.Action == tea.MouseActionPress && tea.MouseEvent(msg).IsWheel() {
 			var cmd tea.Cmd
-			m.previewVP, cmd = m.previewVP.Update(msg)
+			if m.fileMode {
+				m.filesVP, cmd = m.filesVP.Update(msg)
+			} else {
+				m.previewVP, cmd = m.previewVP.Update(msg)
+			}
 			return m, cmd
 		}
 
@@ -251,16 +445,51 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.results, listCmd = m.results.Update(msg)
 		newSelKey := m.selectedKey()
 		if newSelKey != "" && newSelKey != oldSelKey {
-			return m, tea.Batch(listCmd, m.ensurePreviewForSelection())
+			var fileCmd tea.Cmd
+			if m.fileMode {
+				fileCmd = m.exitFileMode()
+			}
+			return m, tea.Batch(listCmd, fileCmd, m.ensurePreviewForSelection(), m.loadMore())
 		}
 		return m, listCmd
 
 	case tea.KeyMsg:
+		m.notice = ""
+		if m.fileMode {
+			return m, m.handleFileKey(msg)
+		}
+		if m.tab == tabInstalled {
+			if cmd, ok := m.handleInstalledKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			return m, tea.Quit
+		case "tab", "shift+tab":
+			if len(m.tabs) > 0 {
+				delta := 1
+				if msg.String() == "shift+tab" {
+					delta = -1
+				}
+				return m, m.switchTab(delta)
+			}
+		case "ctrl+f":
+			return m, m.toggleFileMode()
+		case "ctrl+t":
+			m.hideInstalled = !m.hideInstalled
+			m.setResults(m.shown)
+			return m, m.ensurePreviewForSelection()
 		case "enter":
 			if it, ok := m.results.SelectedItem().(skillItem); ok {
+				if it.denied != nil {
+					m.notice = "⛔ " + it.denied.Error()
+					return m, nil
+				}
+				if it.sourceErr != nil {
+					m.notice = "⚠ " + it.sourceErr.Error()
+					return m, nil
+				}
 				m.result = SearchResult{Selected: true, Skill: it.s}
 				return m, tea.Quit
 			}
@@ -313,21 +542,11 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if len(m.allItems) > 0 {
 				m.searching = false
 				if q == "" {
-					m.results.SetItems(m.allItems)
+					m.setResults(m.allItems)
 				} else {
-					filtered := make([]list.Item, 0, len(m.allItems))
-					needle := strings.ToLower(q)
-					for _, it := range m.allItems {
-						si, ok := it.(skillItem)
-						if !ok {
-							continue
-						}
-						if strings.Contains(strings.ToLower(si.s.SkillID), needle) {
-							filtered = append(filtered, it)
-						}
-					}
-					m.results.SetItems(filtered)
+					m.setResults(fuzzyFilter(m.allItems, q))
 				}
+				m.results.Select(0)
 				previewCmd = m.ensurePreviewForSelection()
 				return m, tea.Batch(inputCmd, listCmd, previewCmd)
 			}
@@ -335,22 +554,25 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// If query changed, debounce a search.
 			m.searchSeq++
 			if q == "" {
+				m.tab = tabPopular
 				m.searching = false
 				// Show popular-by-default.
 				if m.popularItems != nil {
-					m.results.SetItems(m.popularItems)
+					m.setResults(m.popularItems)
 					previewCmd = m.ensurePreviewForSelection()
 				}
 				if !m.popularLoading && m.popularItems == nil {
 					m.popularLoading = true
-					return m, tea.Batch(inputCmd, listCmd, previewCmd, doPopular(m.client, 50))
+					return m, tea.Batch(inputCmd, listCmd, previewCmd, doPopular(m.registry, m.popularPage.want))
 				}
 				return m, tea.Batch(inputCmd, listCmd, previewCmd)
 			}
 
+			m.tab = tabSearch
 			if len([]rune(q)) < 2 {
 				m.searching = false
-				m.results.SetItems([]list.Item{})
+				m.searchItems = nil
+				m.setResults([]list.Item{})
 				m.clearPreview()
 				return m, tea.Batch(inputCmd, listCmd)
 			}
@@ -360,42 +582,72 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}))
 		}
 
-		return m, tea.Batch(inputCmd, listCmd, previewCmd)
+		return m, tea.Batch(inputCmd, listCmd, previewCmd, m.loadMore())
 
 	case triggerSearchMsg:
 		if msg.seq != m.searchSeq {
 			return m, nil
 		}
 		m.searching = true
-		return m, doSearch(m.client, m.searchFunc, msg.query, 10, msg.seq)
+		m.searchQuery = msg.query
+		m.searchPage = pager{want: m.searchLimit}
+		return m, doSearch(m.registry, m.searchFunc, msg.query, m.searchPage.want, msg.seq)
 
 	case searchResultMsg:
 		if msg.seq != m.searchSeq {
 			return m, nil
 		}
+		more := m.searchPage.loading
 		m.searching = false
+		m.searchPage.loading = false
 		m.searchErr = msg.err
-		items := make([]list.Item, 0, len(msg.skills))
-		for _, s := range msg.skills {
-			items = append(items, skillItem{s: s})
+		if msg.err != nil && more {
+			// Keep what's loaded; reaching the end again retries.
+			return m, nil
 		}
-		m.results.SetItems(items)
-		return m, tea.Batch(m.ensurePreviewForSelection())
+		m.searchPage.more = msg.err == nil && len(msg.skills) >= m.searchPage.want
+		m.searchItems = m.itemsFor(msg.skills)
+		if m.tab != tabSearch {
+			return m, m.checkUpdates(m.searchItems)
+		}
+		m.setResults(m.searchItems)
+		return m, tea.Batch(m.ensurePreviewForSelection(), m.checkUpdates(m.searchItems))
 
 	case popularResultMsg:
+		more := m.popularPage.loading
 		m.popularLoading = false
+		m.popularPage.loading = false
 		m.popularErr = msg.err
-		items := make([]list.Item, 0, len(msg.skills))
-		for _, s := range msg.skills {
-			items = append(items, skillItem{s: s})
+		if msg.err != nil && more {
+			return m, nil
+		}
+		m.popularPage.more = msg.err == nil && len(msg.skills) >= m.popularPage.want
+		m.popularItems = m.itemsFor(msg.skills)
+		if m.tab == tabPopular {
+			m.setResults(m.popularItems)
+			return m, tea.Batch(m.ensurePreviewForSelection(), m.checkUpdates(m.popularItems))
 		}
-		m.popularItems = items
-		if strings.TrimSpace(m.input.Value()) == "" {
-			m.results.SetItems(m.popularItems)
-			return m, tea.Batch(m.ensurePreviewForSelection())
+		return m, m.checkUpdates(m.popularItems)
+
+	case updateCheckMsg:
+		if !msg.update {
+			return m, nil
 		}
+		m.updates[msg.folder] = true
+		m.allItems = m.withInstallStates(m.allItems)
+		m.popularItems = m.withInstallStates(m.popularItems)
+		m.searchItems = m.withInstallStates(m.searchItems)
+		m.setResults(m.withInstallStates(m.shown))
 		return m, nil
 
+	case editorDoneMsg:
+		if msg.err != nil {
+			m.notice = "Editor: " + msg.err.Error()
+		}
+		// The skill may have been edited (or renamed) in the meantime.
+		m.reloadInstalled()
+		return m, m.ensurePreviewForSelection()
+
 	case previewResultMsg:
 		if msg.seq != m.previewSeq {
 			return m, nil
@@ -411,6 +663,30 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.rerenderPreview()
 		return m, nil
 
+	case filesResultMsg:
+		if msg.seq != m.fileSeq {
+			return m, nil
+		}
+		m.filesLoading = false
+		m.filesErr = msg.err
+		if msg.err == nil {
+			m.filesKey = msg.key
+			m.files = msg.files
+		}
+		m.renderFileTree()
+		return m, nil
+
+	case fileContentMsg:
+		if msg.seq != m.fileSeq {
+			return m, nil
+		}
+		m.fileLoading = false
+		m.fileErr = msg.err
+		m.fileBody = msg.body
+		m.filesVP.GotoTop()
+		m.renderOpenFile()
+		return m, nil
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -429,9 +705,17 @@ func (m searchModel) View() string {
 		if status == "" {
 			status = "Filter skills • Enter to install • Esc to quit"
 		}
+		if q != "" {
+			status += fmt.Sprintf(" • %d of %d match", len(m.shown), len(m.allItems))
+		}
 	} else {
 		switch {
-		case q == "":
+		case m.tab == tabInstalled:
+			status = "x remove • u update • r reinstall • e edit • Esc to quit"
+			if len(m.installed) == 0 {
+				status = "No skills installed in " + compactPath(m.targetDir)
+			}
+		case m.tab == tabPopular:
 			switch {
 			case m.popularLoading:
 				status = m.spinner.View() + " Popular…"
@@ -440,6 +724,8 @@ func (m searchModel) View() string {
 			default:
 				status = "Popular • Type to search • Enter to install • Esc to quit"
 			}
+		case q == "":
+			status = "Type to search • Esc to quit"
 		case len([]rune(q)) < 2:
 			status = "Type at least 2 characters to search."
 		default:
@@ -454,23 +740,218 @@ func (m searchModel) View() string {
 		}
 	}
 
+	if page := m.pageStatus(); page != "" {
+		status += " • " + page
+	}
+	if !m.snapshotAt.IsZero() {
+		status += " • offline snapshot " + snapshotAge(time.Since(m.snapshotAt))
+	}
+	if len(m.installed) > 0 && m.tab != tabInstalled {
+		if m.hideInstalled {
+			status += " • Ctrl+T show installed"
+		} else {
+			status += " • Ctrl+T hide installed"
+		}
+	}
+	if m.previewPaneW > 0 && m.selectedKey() != "" {
+		status += " • Ctrl+F files"
+	}
+	if m.fileMode {
+		status = "↑/↓ select • Enter open • Esc back • Ctrl+F close files"
+		if m.openFile != "" {
+			status = "↑/↓ scroll • Esc back to files • Ctrl+F close files"
+		}
+	}
+	if m.notice != "" {
+		status = m.notice
+	}
+
 	body := m.bodyView()
 
 	// Intentionally no trailing newline: if we exceed terminal height by one line,
 	// Bubble Tea will clip the top, which can hide the input line.
 	return fmt.Sprintf(
-		"%s\n\n%s\n\n%s",
+		"%s\n%s\n%s\n\n%s",
 		m.input.View(),
+		m.tabsView(),
 		status,
 		body,
 	)
 }
 
+// pagerForTab returns the paging state of the Popular or Search tab.
+func (m *searchModel) pagerForTab() *pager {
+	if len(m.tabs) == 0 {
+		return nil
+	}
+	switch m.tab {
+	case tabPopular:
+		return &m.popularPage
+	case tabSearch:
+		return &m.searchPage
+	}
+	return nil
+}
+
+// loadMore asks for a bigger page once the selection reaches the end of a
+// list that may have more.
+//
+// The registries have no offset, so the next page is fetched by asking for
+// the whole list again with a larger limit; the results replace the list
+// and the selection is kept.
+func (m *searchModel) loadMore() tea.Cmd {
+	p := m.pagerForTab()
+	n := len(m.results.Items())
+	if p == nil || !p.more || p.loading || n == 0 || m.results.Index() < n-1 {
+		return nil
+	}
+	if m.tab == tabSearch {
+		if m.searching {
+			return nil
+		}
+		p.want += m.searchLimit
+		p.loading = true
+		return doSearch(m.registry, m.searchFunc, m.searchQuery, p.want, m.searchSeq)
+	}
+	if m.popularLoading {
+		return nil
+	}
+	p.want += m.popularLimit
+	p.loading = true
+	return doPopular(m.registry, p.want)
+}
+
+// pageStatus reports how many results are loaded. The registries don't
+// report totals, so while more may be available only the loaded count is
+// known.
+func (m searchModel) pageStatus() string {
+	p := m.pagerForTab()
+	if p == nil || len(m.shown) == 0 {
+		return ""
+	}
+	n := len(m.results.Items())
+	status := fmt.Sprintf("all %d loaded", n)
+	if p.more {
+		status = fmt.Sprintf("%d loaded, more available", n)
+	}
+	if p.loading {
+		status += " • " + m.spinner.View() + " loading more…"
+	}
+	return status
+}
+
+// snapshotAge formats d as a coarse "3h old" style label.
+func snapshotAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just pulled"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm old", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh old", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd old", int(d.Hours()/24))
+	}
+}
+
+// itemsFor converts skills to list items, applying the source policy and
+// marking installed skills. Only real denials are hidden or marked as
+// blocked; sources the policy can't evaluate are marked as such.
+func (m searchModel) itemsFor(skills []skillsapi.Skill) []list.Item {
+	items := make([]list.Item, 0, len(skills))
+	for _, s := range skills {
+		it := skillItem{s: s}
+		if err := m.policy.Check(s.Source); errors.Is(err, policy.ErrDenied) {
+			if m.policy.HideDenied() {
+				continue
+			}
+			it.denied = err
+		} else if err != nil {
+			it.sourceErr = err
+		}
+		it.installed, it.installedFrom = m.installStateOf(s)
+		items = append(items, it)
+	}
+	return items
+}
+
+func (m searchModel) installStateOf(s skillsapi.Skill) (installState, string) {
+	inst, ok := m.installed[install.FolderName(s.SkillID)]
+	if !ok {
+		return notInstalled, ""
+	}
+	md := inst.Metadata
+	if md == nil {
+		// Installed by hand: nothing to compare.
+		return installedSame, ""
+	}
+	if strings.TrimSpace(s.Source) != "" && !install.SameSource(s.Source, md.Source) {
+		return installedOtherSource, md.Source
+	}
+	if m.updates[inst.Folder] {
+		return installedUpdate, ""
+	}
+	return installedSame, ""
+}
+
+// withInstallStates re-marks items after an update check.
+func (m searchModel) withInstallStates(items []list.Item) []list.Item {
+	out := make([]list.Item, len(items))
+	for i, it := range items {
+		if si, ok := it.(skillItem); ok {
+			si.installed, si.installedFrom = m.installStateOf(si.s)
+			it = si
+		}
+		out[i] = it
+	}
+	return out
+}
+
+// setResults shows items in the results list, minus installed skills when
+// they're hidden outside the Installed tab.
+func (m *searchModel) setResults(items []list.Item) {
+	m.shown = items
+	if !m.hideInstalled || m.tab == tabInstalled {
+		m.results.SetItems(items)
+		return
+	}
+	visible := make([]list.Item, 0, len(items))
+	for _, it := range items {
+		if si, ok := it.(skillItem); ok && si.installed != notInstalled {
+			continue
+		}
+		visible = append(visible, it)
+	}
+	m.results.SetItems(visible)
+}
+
+// checkUpdates starts update checks for the installed skills among items
+// that haven't been checked yet.
+func (m searchModel) checkUpdates(items []list.Item) tea.Cmd {
+	if m.checkUpdate == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, it := range items {
+		si, ok := it.(skillItem)
+		if !ok || si.installed != installedSame {
+			continue
+		}
+		inst := m.installed[install.FolderName(si.s.SkillID)]
+		if inst.Metadata == nil || m.updateChecked[inst.Folder] {
+			continue
+		}
+		m.updateChecked[inst.Folder] = true
+		cmds = append(cmds, doUpdateCheck(m.checkUpdate, inst.Folder, *inst.Metadata))
+	}
+	return tea.Batch(cmds...)
+}
+
 func (m searchModel) isInPreviewPane(msg tea.MouseMsg) bool {
 	if m.previewPaneW <= 0 {
 		return false
 	}
-	// Layout: input + blank line + status + blank line take 4 rows.
+	// Layout: input + tabs (or blank) line + status + blank line take 4 rows.
 	if msg.Y < 4 {
 		return false
 	}
@@ -504,26 +985,32 @@ func (m searchModel) bodyView() string {
 }
 
 func (m searchModel) previewIndicatorLine() string {
-	w := m.previewVP.Width
+	return scrollIndicatorLine(m.previewVP)
+}
+
+// scrollIndicatorLine renders a centered "──── ▲  12% ▼ ────" bar for vp, or a
+// blank line when its content fits.
+func scrollIndicatorLine(vp viewport.Model) string {
+	w := vp.Width
 	if w <= 0 {
 		return ""
 	}
 
 	// Only show indicators if the content can actually scroll.
-	scrollable := m.previewVP.TotalLineCount() > m.previewVP.Height
+	scrollable := vp.TotalLineCount() > vp.Height
 	if !scrollable {
 		return lipgloss.NewStyle().Width(w).Render("")
 	}
 
 	up := " "
-	if !m.previewVP.AtTop() {
+	if !vp.AtTop() {
 		up = "▲"
 	}
 	down := " "
-	if !m.previewVP.AtBottom() {
+	if !vp.AtBottom() {
 		down = "▼"
 	}
-	pct := int(m.previewVP.ScrollPercent()*100 + 0.5)
+	pct := int(vp.ScrollPercent()*100 + 0.5)
 	core := fmt.Sprintf("%s %3d%% %s", up, pct, down)
 
 	// Build a centered bar like: ──── ▲  12% ▼ ────
@@ -548,15 +1035,16 @@ func (m searchModel) previewView() string {
 		return ""
 	}
 
+	if m.fileMode {
+		return m.filesView()
+	}
+
 	if m.previewLoading {
 		return m.spinner.View() + " Loading preview…"
 	}
 
 	if m.previewErr != nil {
-		if errors.Is(m.previewErr, skillsapi.ErrPreviewUnavailable) {
-			return "Preview unavailable"
-		}
-		return "Preview unavailable: " + m.previewErr.Error()
+		return unavailableText("Preview", m.previewErr)
 	}
 
 	if strings.TrimSpace(m.previewRendered) == "" {
@@ -575,6 +1063,18 @@ func (m searchModel) previewView() string {
 	return previewBlock
 }
 
+// unavailableText explains why what can't be shown.
+func unavailableText(what string, err error) string {
+	var rl *skillsapi.RateLimitError
+	if errors.As(err, &rl) {
+		return what + " unavailable: " + rl.Error()
+	}
+	if errors.Is(err, skillsapi.ErrPreviewUnavailable) {
+		return what + " unavailable"
+	}
+	return what + " unavailable: " + err.Error()
+}
+
 func (m *searchModel) clearPreview() {
 	m.previewLoading = false
 	m.previewKey = ""
@@ -607,20 +1107,21 @@ func (m *searchModel) rerenderPreview() {
 	m.previewVP.SetContent(m.previewRendered)
 }
 
-func (m *searchModel) selectedSkill() (skillsapi.Skill, bool) {
+func (m *searchModel) selectedKey() string {
 	it, ok := m.results.SelectedItem().(skillItem)
 	if !ok {
-		return skillsapi.Skill{}, false
+		return ""
 	}
-	return it.s, true
+	return previewKeyForItem(it)
 }
 
-func (m *searchModel) selectedKey() string {
-	s, ok := m.selectedSkill()
-	if !ok {
-		return ""
+// previewKeyForItem keeps previews of installed copies apart from the
+// registry's.
+func previewKeyForItem(it skillItem) string {
+	if it.local != nil {
+		return "local|" + it.local.Path
 	}
-	return previewKeyForSkill(s)
+	return previewKeyForSkill(it.s)
 }
 
 func previewKeyForSkill(s skillsapi.Skill) string {
@@ -628,15 +1129,35 @@ func previewKeyForSkill(s skillsapi.Skill) string {
 }
 
 func (m *searchModel) ensurePreviewForSelection() tea.Cmd {
-	s, ok := m.selectedSkill()
+	it, ok := m.results.SelectedItem().(skillItem)
 	if !ok {
 		m.clearPreview()
 		return nil
 	}
+	s := it.s
 
-	key := previewKeyForSkill(s)
+	key := previewKeyForItem(it)
 	m.lastSelKey = key
 
+	if it.local != nil {
+		// Installed copies are previewed from disk, uncached: they may be
+		// edited while the UI is open.
+		b, err := os.ReadFile(filepath.Join(it.local.Path, "SKILL.md"))
+		m.previewLoading = false
+		m.previewErr = nil
+		m.previewKey = key
+		m.previewMarkdown = string(b)
+		m.previewRendered = ""
+		m.previewVP.GotoTop()
+		m.previewVP.SetContent("")
+		if err != nil {
+			m.previewErr = skillsapi.ErrPreviewUnavailable
+			return nil
+		}
+		m.rerenderPreview()
+		return nil
+	}
+
 	if md, ok := m.previewCache[key]; ok {
 		m.previewLoading = false
 		m.previewErr = nil
@@ -656,7 +1177,7 @@ func (m *searchModel) ensurePreviewForSelection() tea.Cmd {
 	m.previewRendered = ""
 	m.previewVP.GotoTop()
 	m.previewVP.SetContent("")
-	return doPreview(m.client, m.previewFunc, s, key, seq)
+	return doPreview(m.registry, m.previewFunc, s, key, seq)
 }
 
 func renderMarkdownANSI(md string, wrap int) (string, error) {
@@ -717,38 +1238,49 @@ func newTerminalListDelegate() list.DefaultDelegate {
 	return d
 }
 
-func doSearch(client skillsapi.Client, searchFn func(context.Context, string, int) ([]skillsapi.Skill, error), query string, limit int, seq int) tea.Cmd {
+func doSearch(registry skillsapi.Registry, searchFn func(context.Context, string, int) ([]skillsapi.Skill, error), query string, limit int, seq int) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
 		defer cancel()
 
 		fn := searchFn
 		if fn == nil {
-			fn = client.Search
+			fn = registry.Search
 		}
 		skills, err := fn(ctx, query, limit)
 		return searchResultMsg{seq: seq, skills: skills, err: err}
 	}
 }
 
-func doPopular(client skillsapi.Client, limit int) tea.Cmd {
+func doPopular(registry skillsapi.Registry, limit int) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		skills, err := client.Popular(ctx, limit)
+		skills, err := registry.Popular(ctx, limit)
 		return popularResultMsg{skills: skills, err: err}
 	}
 }
 
-func doPreview(client skillsapi.Client, previewFn func(context.Context, skillsapi.Skill) (string, error), skill skillsapi.Skill, key string, seq int) tea.Cmd {
+func doUpdateCheck(check func(context.Context, install.Metadata) (bool, error), folder string, md install.Metadata) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		// A failed check just leaves the row marked as installed.
+		update, err := check(ctx, md)
+		return updateCheckMsg{folder: folder, update: err == nil && update}
+	}
+}
+
+func doPreview(registry skillsapi.Registry, previewFn func(context.Context, skillsapi.Skill) (string, error), skill skillsapi.Skill, key string, seq int) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
 		defer cancel()
 
 		fn := previewFn
 		if fn == nil {
-			fn = client.FetchSkillMarkdown
+			fn = registry.FetchSkillMarkdown
 		}
 		md, err := fn(ctx, skill)
 		return previewResultMsg{seq: seq, key: key, md: md, err: err}
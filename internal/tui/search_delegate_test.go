@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaofelix/skulls/internal/skillsapi"
 )
 
 func TestNewTerminalListDelegate_UsesTerminalANSIAndNoAdaptiveColors(t *testing.T) {
@@ -27,3 +29,10 @@ func TestNewTerminalListDelegate_UsesTerminalANSIAndNoAdaptiveColors(t *testing.
 		t.Fatalf("selected desc should not use adaptive color")
 	}
 }
+
+func TestSkillItem_DescriptionShowsRegistryLabel(t *testing.T) {
+	it := skillItem{s: skillsapi.Skill{SkillID: "a", Source: "team/skills", Installs: 3, Registry: "internal"}}
+	if got := it.Description(); got != "team/skills • 3 installs • [internal]" {
+		t.Fatalf("description=%q", got)
+	}
+}
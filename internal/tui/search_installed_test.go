@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kaofelix/skulls/internal/install"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+// writeInstalled fakes an install of skillID from source into target.
+func writeInstalled(t *testing.T, target string, skillID string, source string) {
+	t.Helper()
+	dir := filepath.Join(target, skillID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: "+skillID+"\ndescription: test\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(target, install.MetadataDirName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	md := `{"skill_id": "` + skillID + `", "source": "` + source + `", "commit": "abc"}`
+	if err := os.WriteFile(filepath.Join(target, install.MetadataDirName, skillID+".json"), []byte(md), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchModel_MarksInstalledSkills(t *testing.T) {
+	target := t.TempDir()
+	writeInstalled(t, target, "same", "acme/skills")
+	writeInstalled(t, target, "forked", "someone/fork")
+	writeInstalled(t, target, "stale", "acme/skills")
+
+	checked := map[string]bool{}
+	m := newSearchModelWithOptions(SearchOptions{
+		TargetDir: target,
+		InitialSkills: []skillsapi.Skill{
+			{SkillID: "same", Source: "https://github.com/acme/skills"},
+			{SkillID: "forked", Source: "acme/skills"},
+			{SkillID: "stale", Source: "acme/skills"},
+			{SkillID: "new", Source: "acme/skills"},
+		},
+		CheckUpdate: func(_ context.Context, md install.Metadata) (bool, error) {
+			checked[md.SkillID] = true
+			return md.SkillID == "stale", nil
+		},
+	})
+
+	descriptions := func() []string {
+		var out []string
+		for _, it := range m.results.Items() {
+			out = append(out, it.(skillItem).Description())
+		}
+		return out
+	}
+	got := descriptions()
+	if got[0] != "✓ installed • https://github.com/acme/skills" || got[1] != "✓ installed from someone/fork • acme/skills" || got[3] != "acme/skills" {
+		t.Fatalf("descriptions=%q", got)
+	}
+
+	// Only rows installed from the same source are checked for updates.
+	for _, msg := range collectMsgs(m.checkUpdates(m.allItems)) {
+		next, _ := m.Update(msg)
+		m = next.(searchModel)
+	}
+	if checked["forked"] || checked["new"] || !checked["stale"] {
+		t.Fatalf("checked=%v", checked)
+	}
+	if got := descriptions(); got[2] != "↑ update available • acme/skills" {
+		t.Fatalf("descriptions=%q", got)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = next.(searchModel)
+	if items := m.results.Items(); len(items) != 1 || items[0].(skillItem).s.SkillID != "new" {
+		t.Fatalf("expected only the uninstalled skill, got %d items", len(items))
+	}
+	if v := m.View(); !strings.Contains(v, "Ctrl+T show installed") {
+		t.Fatalf("expected toggle hint in status, got:\n%s", v)
+	}
+}
+
+// collectMsgs runs cmd, expanding batches, and returns the messages.
+func collectMsgs(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var out []tea.Msg
+		for _, c := range batch {
+			out = append(out, collectMsgs(c)...)
+		}
+		return out
+	}
+	return []tea.Msg{msg}
+}
+
+func TestSearchModel_InstalledTabManagesSkills(t *testing.T) {
+	target := t.TempDir()
+	writeInstalled(t, target, "alpha", "acme/skills")
+	writeInstalled(t, target, "beta", "acme/skills")
+
+	m := newSearchModelWithOptions(SearchOptions{TargetDir: target})
+	m.previewPaneW = 40
+	press := func(k tea.KeyMsg) tea.Cmd {
+		next, cmd := m.Update(k)
+		m = next.(searchModel)
+		return cmd
+	}
+
+	// Popular → Search → Installed, and back around with shift+tab.
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	if m.tab != tabSearch {
+		t.Fatalf("tab=%v", m.tab)
+	}
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	if m.tab != tabInstalled || len(m.results.Items()) != 2 {
+		t.Fatalf("tab=%v items=%d", m.tab, len(m.results.Items()))
+	}
+	if !strings.Contains(m.View(), "[Installed (2)]") {
+		t.Fatalf("expected active Installed tab, got:\n%s", m.View())
+	}
+	if !strings.Contains(m.previewMarkdown, "name: alpha") {
+		t.Fatalf("expected local SKILL.md preview, got %q", m.previewMarkdown)
+	}
+
+	// Remove needs confirming.
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if _, err := os.Stat(filepath.Join(target, "alpha")); err != nil {
+		t.Fatalf("removed without confirmation: %v", err)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if _, err := os.Stat(filepath.Join(target, "alpha")); !os.IsNotExist(err) {
+		t.Fatalf("expected alpha to be removed, err=%v", err)
+	}
+	if items := m.results.Items(); len(items) != 1 || items[0].(skillItem).s.SkillID != "beta" {
+		t.Fatalf("items after remove=%d", len(items))
+	}
+
+	cmd := press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if cmd == nil || !m.result.Selected || m.result.Action != ActionUpdate || m.result.Installed == nil || m.result.Installed.Folder != "beta" {
+		t.Fatalf("result=%+v", m.result)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.tab != tabSearch {
+		t.Fatalf("tab=%v", m.tab)
+	}
+}
+
+func TestSearchModel_SourceSelectorHasNoTabs(t *testing.T) {
+	m := newSearchModelWithOptions(SearchOptions{
+		InitialSkills: []skillsapi.Skill{{SkillID: "a", Source: "o/r"}},
+		TargetDir:     t.TempDir(),
+	})
+	if len(m.tabs) != 0 {
+		t.Fatalf("tabs=%v", m.tabs)
+	}
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+func TestSearchModel_LoadsMoreAtEndOfList(t *testing.T) {
+	snap := skillsapi.Snapshot{}
+	for i := range 3 {
+		snap.Skills = append(snap.Skills, skillsapi.SnapshotSkill{Skill: skillsapi.Skill{SkillID: fmt.Sprintf("popular-%d", i), Source: "acme/skills", Installs: 10 - i}})
+	}
+	var searchLimits []int
+	m := newSearchModelWithOptions(SearchOptions{
+		Registry:     snap,
+		PopularLimit: 2,
+		SearchLimit:  2,
+		SearchFunc: func(_ context.Context, q string, limit int) ([]skillsapi.Skill, error) {
+			searchLimits = append(searchLimits, limit)
+			var out []skillsapi.Skill
+			for i := range min(limit, 3) {
+				out = append(out, skillsapi.Skill{SkillID: fmt.Sprintf("%s-%d", q, i), Source: "acme/skills"})
+			}
+			return out, nil
+		},
+	})
+	update := func(msg tea.Msg) tea.Cmd {
+		t.Helper()
+		next, cmd := m.Update(msg)
+		m = next.(searchModel)
+		return cmd
+	}
+	// Feeds back only the paging responses; other commands (cursor blink,
+	// previews) don't matter here.
+	runPaging := func(cmd tea.Cmd) {
+		t.Helper()
+		for _, msg := range collectMsgs(cmd) {
+			switch msg.(type) {
+			case popularResultMsg, searchResultMsg:
+				update(msg)
+			}
+		}
+	}
+	update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	update(doPopular(m.registry, m.popularPage.want)())
+
+	if got := m.View(); !strings.Contains(got, "2 loaded, more available") {
+		t.Fatalf("expected partial popular list, got:\n%s", got)
+	}
+
+	runPaging(update(tea.KeyMsg{Type: tea.KeyDown}))
+	if n := len(m.results.Items()); n != 3 || m.results.Index() != 1 {
+		t.Fatalf("expected 3 items with the selection kept, got %d at %d", n, m.results.Index())
+	}
+	if got := m.View(); !strings.Contains(got, "all 3 loaded") || strings.Contains(got, "more available") {
+		t.Fatalf("expected complete popular list, got:\n%s", got)
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("pdf")})
+	update(triggerSearchMsg{seq: m.searchSeq, query: "pdf"})
+	update(doSearch(m.registry, m.searchFunc, "pdf", m.searchPage.want, m.searchSeq)())
+	if got := m.View(); !strings.Contains(got, "2 loaded, more available") {
+		t.Fatalf("expected partial search results, got:\n%s", got)
+	}
+
+	runPaging(update(tea.KeyMsg{Type: tea.KeyDown}))
+	runPaging(update(tea.KeyMsg{Type: tea.KeyDown}))
+	if n := len(m.results.Items()); n != 3 {
+		t.Fatalf("expected 3 search results, got %d", n)
+	}
+	if fmt.Sprint(searchLimits) != "[2 4]" {
+		t.Fatalf("search limits=%v", searchLimits)
+	}
+}
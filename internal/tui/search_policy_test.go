@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaofelix/skulls/internal/policy"
+	"github.com/kaofelix/skulls/internal/skillsapi"
+)
+
+func TestSearchModel_MarksDeniedSkills(t *testing.T) {
+	m := newSearchModelWithOptions(SearchOptions{
+		Policy: policy.Set{{Deny: []policy.Rule{{Owner: "evil"}}}},
+	})
+
+	items := m.itemsFor([]skillsapi.Skill{
+		{SkillID: "good", Source: "acme/skills"},
+		{SkillID: "bad", Source: "evil/skills"},
+	})
+	if len(items) != 2 {
+		t.Fatalf("got %d items", len(items))
+	}
+	if items[0].(skillItem).denied != nil {
+		t.Fatalf("expected first skill to be allowed")
+	}
+	bad := items[1].(skillItem)
+	if bad.denied == nil {
+		t.Fatalf("expected second skill to be denied")
+	}
+	if got := bad.Description(); got != "⛔ blocked by policy • evil/skills" {
+		t.Fatalf("description=%q", got)
+	}
+}
+
+func TestSearchModel_MarksUnrecognizedSourcesApartFromDenials(t *testing.T) {
+	m := newSearchModelWithOptions(SearchOptions{
+		Policy: policy.Set{{Deny: []policy.Rule{{Owner: "evil"}}, HideDenied: true}},
+	})
+
+	items := m.itemsFor([]skillsapi.Skill{{SkillID: "odd", Source: "not a source"}})
+	if len(items) != 1 {
+		t.Fatalf("an unrecognized source must not be hidden as denied, items=%+v", items)
+	}
+	it := items[0].(skillItem)
+	if it.denied != nil || it.sourceErr == nil {
+		t.Fatalf("denied=%v sourceErr=%v", it.denied, it.sourceErr)
+	}
+	if got := it.Description(); !strings.HasPrefix(got, "⚠ unrecognized source") {
+		t.Fatalf("description=%q", got)
+	}
+}
+
+func TestSearchModel_HidesDeniedSkillsWhenConfigured(t *testing.T) {
+	m := newSearchModelWithOptions(SearchOptions{
+		Policy: policy.Set{{Deny: []policy.Rule{{Owner: "evil"}}, HideDenied: true}},
+	})
+
+	items := m.itemsFor([]skillsapi.Skill{
+		{SkillID: "good", Source: "acme/skills"},
+		{SkillID: "bad", Source: "evil/skills"},
+	})
+	if len(items) != 1 || items[0].(skillItem).s.SkillID != "good" {
+		t.Fatalf("items=%+v", items)
+	}
+}
+
+func TestSearchModel_StatusShowsSnapshotAge(t *testing.T) {
+	m := newSearchModelWithOptions(SearchOptions{
+		InitialSkills: []skillsapi.Skill{{SkillID: "a", Source: "o/r"}},
+		SnapshotAt:    time.Now().Add(-3 * 24 * time.Hour),
+	})
+	if v := m.View(); !strings.Contains(v, "offline snapshot 3d old") {
+		t.Fatalf("expected snapshot age in status, got:\n%s", v)
+	}
+}
+
+func TestSearchModel_PreviewShowsRateLimit(t *testing.T) {
+	m := newSearchModelWithOptions(SearchOptions{
+		InitialSkills: []skillsapi.Skill{{SkillID: "a", Source: "o/r"}},
+	})
+	m.previewErr = &skillsapi.RateLimitError{}
+	if v := m.previewView(); !strings.Contains(v, "rate limit") {
+		t.Fatalf("expected rate limit message, got %q", v)
+	}
+}
@@ -3,16 +3,24 @@ package tui
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/kaofelix/skulls/internal/fsutil"
 	"github.com/kaofelix/skulls/internal/install"
 	"github.com/kaofelix/skulls/internal/skillsapi"
 )
 
 // RunSearchFromSource opens the interactive selector using skills discovered
 // from a repository source (local path or git source).
-func RunSearchFromSource(source string) (SearchResult, error) {
-	discovered, cleanup, err := install.DiscoverSkills(source)
+func RunSearchFromSource(ctx context.Context, source string, opts install.Options) (SearchResult, error) {
+	discovered, cleanup, err := install.DiscoverSkills(ctx, source, install.DiscoverOptions{
+		Ref:    opts.Ref,
+		Tokens: opts.Tokens,
+		SHA256: opts.SHA256,
+		Policy: opts.Policy,
+		Git:    opts.Git,
+	})
 	if cleanup != nil {
 		defer cleanup()
 	}
@@ -23,7 +31,7 @@ func RunSearchFromSource(source string) (SearchResult, error) {
 	skills := make([]skillsapi.Skill, 0, len(discovered))
 	filesBySkill := make(map[string]string, len(discovered))
 	for _, d := range discovered {
-		skills = append(skills, skillsapi.Skill{Source: source, SkillID: d.Name, Name: d.Name})
+		skills = append(skills, skillsapi.Skill{Source: source, SkillID: d.Name, Name: d.Name, Description: d.Description, Path: d.Path})
 		filesBySkill[d.Name] = d.SkillFilePath
 	}
 
@@ -39,10 +47,44 @@ func RunSearchFromSource(source string) (SearchResult, error) {
 		return string(b), nil
 	}
 
+	// The file tree leaves out what installs leave out. Every skill is in
+	// the same repository.
+	var root string
+	var ignore *fsutil.Ignore
+	if len(discovered) > 0 {
+		root = repoRoot(discovered[0])
+		ignore, _ = fsutil.LoadGitignore(root)
+	}
+	listFiles := func(_ context.Context, skill skillsapi.Skill) (skillsapi.SkillFiles, error) {
+		p, ok := filesBySkill[strings.TrimSpace(skill.SkillID)]
+		if !ok {
+			return skillsapi.SkillFiles{}, skillsapi.ErrPreviewUnavailable
+		}
+		return listLocalSkillFiles(root, filepath.Dir(p), ignore)
+	}
+
 	return RunSearchWithOptions(SearchOptions{
 		InitialSkills: skills,
 		Placeholder:   "Filter skills…",
 		StatusHint:    "Filter repository skills • Enter to install • Esc to quit",
 		PreviewFunc:   preview,
+		ListFilesFunc: listFiles,
+		FetchFileFunc: readLocalSkillFile,
+		TargetDir:     opts.TargetDir,
+		CheckUpdate: func(ctx context.Context, md install.Metadata) (bool, error) {
+			return install.UpdateAvailable(ctx, md, opts)
+		},
 	})
 }
+
+// repoRoot returns the repository d was discovered in; d.Path is relative
+// to it.
+func repoRoot(d install.DiscoveredSkill) string {
+	root := d.SkillDirPath
+	for _, seg := range strings.Split(d.Path, "/") {
+		if seg != "" && seg != "." {
+			root = filepath.Dir(root)
+		}
+	}
+	return root
+}